@@ -2,17 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/infra/api/web/controller/auction_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/bid_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/user_controller"
 	"fullcycle-auction_go/internal/infra/database/auction"
 	"fullcycle-auction_go/internal/infra/database/bid"
 	"fullcycle-auction_go/internal/infra/database/user"
+	"fullcycle-auction_go/internal/infra/wal"
 	"fullcycle-auction_go/internal/usecase/auction_usecase"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
 	"fullcycle-auction_go/internal/usecase/user_usecase"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -33,25 +42,119 @@ func main() {
 		return
 	}
 
+	var bidLog bid_entity.BidWriteAheadLog
+	var fileBidLog *wal.FileBidLog
+	if bidLogPath := os.Getenv("BID_WAL_PATH"); bidLogPath != "" {
+		fileBidLog, err = wal.NewFileBidLog(bidLogPath)
+		if err != nil {
+			log.Fatal(err.Error())
+			return
+		}
+		bidLog = fileBidLog
+	}
+
 	router := gin.Default()
 
-	userController, bidController, auctionsController := initDependencies(databaseConnection)
+	userController, bidController, auctionsController, auctionScheduler, auctionPriceClock, bidUseCase :=
+		initDependencies(databaseConnection, bidLog)
+
+	if err := auctionScheduler.Start(ctx); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	if err := auctionPriceClock.Start(ctx); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
 
 	router.GET("/auction", auctionsController.FindAuctions)
 	router.GET("/auction/:auctionId", auctionsController.FindAuctionById)
 	router.POST("/auction", auctionsController.CreateAuction)
+	router.PUT("/auction/:auctionId", auctionsController.UpdateAuction)
+	router.PATCH("/auction/:auctionId", auctionsController.PatchAuction)
+	router.GET("/auction/:auctionId/revisions", auctionsController.FindAuctionRevisions)
 	router.GET("/auction/winner/:auctionId", auctionsController.FindWinningBidByAuctionId)
+	router.POST("/auction/:auctionId/accept", auctionsController.AcceptPrice)
+	router.POST("/auction/:auctionId/buy", auctionsController.BuyNow)
+	router.POST("/auction/:auctionId/end", auctionsController.EndAuction)
+	router.POST("/auction/:auctionId/cancel", auctionsController.CancelAuction)
+	router.POST("/auction/:auctionId/pause", auctionsController.PauseAuction)
+	router.POST("/auction/:auctionId/resume", auctionsController.ResumeAuction)
+	router.POST("/auction/:auctionId/relist", auctionsController.RelistAuction)
+	router.POST("/auction/:auctionId/publish", auctionsController.PublishAuction)
 	router.POST("/bid", bidController.CreateBid)
+	router.POST("/bid/proxy", bidController.CreateProxyBid)
 	router.GET("/bid/:auctionId", bidController.FindBidByAuctionId)
+	router.GET("/bid/status/:bidId", bidController.FindBidStatus)
+	router.GET("/bid/queue/stats", bidController.FindBidQueueStats)
 	router.GET("/user/:userId", userController.FindUserById)
 
-	router.Run(":8080")
+	server := &http.Server{
+		Addr:    ":8080",
+		Handler: router,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-signalCtx.Done()
+
+	logger.Info("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, getShutdownTimeout())
+	defer cancel()
+
+	// A ordem importa: primeiro param as requisições, depois a fila de lances
+	// é esvaziada (o que ainda pode estender leilões), e só então os timers
+	// e a conexão com o banco são encerrados.
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error trying to shut down http server", err)
+	}
+
+	if err := bidUseCase.Close(shutdownCtx); err != nil {
+		logger.Error("Error trying to flush pending bids", err)
+	}
+
+	auctionScheduler.Stop()
+	auctionPriceClock.Stop()
+
+	if fileBidLog != nil {
+		if err := fileBidLog.Close(); err != nil {
+			logger.Error("Error trying to close bid write-ahead log", err)
+		}
+	}
+
+	if err := databaseConnection.Client().Disconnect(shutdownCtx); err != nil {
+		logger.Error("Error trying to disconnect from mongodb", err)
+	}
+
+	logger.Info("Server stopped")
 }
 
-func initDependencies(database *mongo.Database) (
+// getShutdownTimeout obtém das variáveis de ambiente o tempo máximo para o
+// encerramento gracioso
+func getShutdownTimeout() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil {
+		return 30 * time.Second
+	}
+
+	return duration
+}
+
+func initDependencies(database *mongo.Database, bidLog bid_entity.BidWriteAheadLog) (
 	userController *user_controller.UserController,
 	bidController *bid_controller.BidController,
-	auctionController *auction_controller.AuctionController) {
+	auctionController *auction_controller.AuctionController,
+	auctionScheduler *auction.AuctionScheduler,
+	auctionPriceClock *auction.AuctionPriceClock,
+	bidUseCase bid_usecase.BidUseCaseInterface) {
 
 	auctionRepository := auction.NewAuctionRepository(database)
 	bidRepository := bid.NewBidRepository(database, auctionRepository)
@@ -59,9 +162,15 @@ func initDependencies(database *mongo.Database) (
 
 	userController = user_controller.NewUserController(
 		user_usecase.NewUserUseCase(userRepository))
-	auctionController = auction_controller.NewAuctionController(
-		auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository))
-	bidController = bid_controller.NewBidController(bid_usecase.NewBidUseCase(bidRepository))
+	auctionUseCase := auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository)
+	auctionRepository.Scheduler.OnAuctionClosed(auctionUseCase.SettleAuction)
+
+	auctionController = auction_controller.NewAuctionController(auctionUseCase)
+	bidUseCase = bid_usecase.NewBidUseCase(bidRepository, bidLog)
+	auctionRepository.Scheduler.OnAuctionClosing(bidUseCase.FlushAuction)
+	bidController = bid_controller.NewBidController(bidUseCase)
+	auctionScheduler = auctionRepository.Scheduler
+	auctionPriceClock = auctionRepository.PriceClock
 
 	return
 }
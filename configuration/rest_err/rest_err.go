@@ -24,9 +24,19 @@ func (r *RestErr) Error() string {
 func ConvertError(internalError *internal_error.InternalError) *RestErr {
 	switch internalError.Err {
 	case "bad_request":
-		return NewBadRequestError(internalError.Error())
+		var causes []Causes
+		for _, cause := range internalError.Causes {
+			causes = append(causes, Causes{
+				Field:   cause.Field,
+				Message: cause.Message,
+			})
+		}
+
+		return NewBadRequestError(internalError.Error(), causes...)
 	case "not_found":
 		return NewNotFoundError(internalError.Error())
+	case "service_unavailable":
+		return NewServiceUnavailableError(internalError.Error())
 	default:
 		return NewInternalServerError(internalError.Error())
 	}
@@ -58,3 +68,12 @@ func NewNotFoundError(message string) *RestErr {
 		Causes:  nil,
 	}
 }
+
+func NewServiceUnavailableError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "service_unavailable",
+		Code:    http.StatusServiceUnavailable,
+		Causes:  nil,
+	}
+}
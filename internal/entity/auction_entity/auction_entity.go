@@ -2,15 +2,124 @@ package auction_entity
 
 import (
 	"context"
+	"fmt"
 	"fullcycle-auction_go/internal/internal_error"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// AuctionOption configura atributos opcionais do leilão na criação
+type AuctionOption func(*Auction)
+
+// WithStartsAt define o início do leilão; por padrão ele começa na criação
+func WithStartsAt(startsAt time.Time) AuctionOption {
+	return func(au *Auction) {
+		au.StartsAt = startsAt
+	}
+}
+
+// WithEndsAt define o término do leilão; quando omitido o repositório
+// aplica a duração padrão (AUCTION_INTERVAL) a partir do início
+func WithEndsAt(endsAt time.Time) AuctionOption {
+	return func(au *Auction) {
+		au.EndsAt = endsAt
+	}
+}
+
+// WithReservePrice define o preço mínimo oculto para que o leilão tenha venda
+func WithReservePrice(reservePrice float64) AuctionOption {
+	return func(au *Auction) {
+		au.ReservePrice = reservePrice
+	}
+}
+
+// WithStartingPrice define o valor mínimo do primeiro lance
+func WithStartingPrice(startingPrice float64) AuctionOption {
+	return func(au *Auction) {
+		au.StartingPrice = startingPrice
+	}
+}
+
+// WithMinIncrement define o incremento mínimo sobre o maior lance
+func WithMinIncrement(minIncrement float64) AuctionOption {
+	return func(au *Auction) {
+		au.MinIncrement = minIncrement
+	}
+}
+
+// WithIncrementTiers define incrementos por faixa de preço; fora das faixas
+// vale o incremento mínimo
+func WithIncrementTiers(incrementTiers []IncrementTier) AuctionOption {
+	return func(au *Auction) {
+		au.IncrementTiers = incrementTiers
+	}
+}
+
+// WithAuctionType define o formato do leilão; o padrão é o inglês (aberto e
+// ascendente)
+func WithAuctionType(auctionType AuctionType) AuctionOption {
+	return func(au *Auction) {
+		au.Type = auctionType
+	}
+}
+
+// WithPriceDrop define quanto o preço do leilão holandês cai a cada intervalo
+func WithPriceDrop(priceDecrement float64, priceDropInterval time.Duration) AuctionOption {
+	return func(au *Auction) {
+		au.PriceDecrement = priceDecrement
+		au.PriceDropInterval = priceDropInterval
+	}
+}
+
+// WithQuantity define quantas unidades idênticas o leilão oferece
+func WithQuantity(quantity int) AuctionOption {
+	return func(au *Auction) {
+		au.Quantity = quantity
+	}
+}
+
+// WithPricingRule define quanto pagam os vencedores de um leilão de várias
+// unidades; o padrão é cada um pagar o próprio lance
+func WithPricingRule(pricingRule PricingRule) AuctionOption {
+	return func(au *Auction) {
+		au.PricingRule = pricingRule
+	}
+}
+
+// WithBuyNowPrice define o preço pelo qual um comprador pode encerrar o
+// leilão na hora, enquanto os lances não chegam a ele
+func WithBuyNowPrice(buyNowPrice float64) AuctionOption {
+	return func(au *Auction) {
+		au.BuyNowPrice = buyNowPrice
+	}
+}
+
+// WithAutoRelist faz o leilão que termina sem lances ser relistado
+// automaticamente até maxRelists vezes, com os preços reduzidos em
+// priceReduction por cento a cada relistagem
+func WithAutoRelist(maxRelists int, priceReduction float64) AuctionOption {
+	return func(au *Auction) {
+		au.MaxAutoRelists = maxRelists
+		au.RelistPriceReduction = priceReduction
+	}
+}
+
+// WithDuration define a duração de um rascunho, contada a partir do início
+// fixado na publicação
+func WithDuration(duration time.Duration) AuctionOption {
+	return func(au *Auction) {
+		au.DraftDuration = duration
+	}
+}
+
 func CreateAuction(
 	productName, category, description string,
-	condition ProductCondition) (*Auction, *internal_error.InternalError) {
+	condition ProductCondition,
+	options ...AuctionOption) (*Auction, *internal_error.InternalError) {
+	now := time.Now()
 	auction := &Auction{
 		Id:          uuid.New().String(),
 		ProductName: productName,
@@ -18,16 +127,327 @@ func CreateAuction(
 		Description: description,
 		Condition:   condition,
 		Status:      Active,
-		Timestamp:   time.Now(),
+		Timestamp:   now,
+		StartsAt:    now,
+		Quantity:    1,
+		Version:     1,
 	}
 
-	if err := auction.Validate(); err != nil {
+	if err := auction.applyOptions(now, options); err != nil {
 		return nil, err
 	}
 
 	return auction, nil
 }
 
+// CreateDraftAuction cria um rascunho sem as validações do leilão, que só
+// são aplicadas na publicação (Publish). Início e término ficam vazios, a
+// menos que informados.
+func CreateDraftAuction(
+	productName, category, description string,
+	condition ProductCondition,
+	options ...AuctionOption) *Auction {
+	auction := &Auction{
+		Id:          uuid.New().String(),
+		ProductName: productName,
+		Category:    category,
+		Description: description,
+		Condition:   condition,
+		Status:      Draft,
+		Timestamp:   time.Now(),
+		Quantity:    1,
+		Version:     1,
+	}
+
+	for _, option := range options {
+		option(auction)
+	}
+
+	return auction
+}
+
+// Publish valida o rascunho e o coloca no ar: o início sem data (ou já
+// passado) passa a ser now e a duração do rascunho é contada a partir dele.
+// O rascunho só é alterado se for válido.
+func (au *Auction) Publish(now time.Time) *internal_error.InternalError {
+	if au.Status != Draft {
+		return internal_error.NewBadRequestError("Only draft auctions can be published")
+	}
+
+	published := *au
+	published.Status = Active
+	if published.StartsAt.IsZero() || published.StartsAt.Before(now) {
+		published.StartsAt = now
+	}
+	if published.DraftDuration > 0 {
+		published.EndsAt = published.StartsAt.Add(published.DraftDuration)
+	}
+	published.IncrementTiers = append([]IncrementTier(nil), au.IncrementTiers...)
+
+	if err := published.applyOptions(now, nil); err != nil {
+		return err
+	}
+
+	*au = published
+
+	return nil
+}
+
+// Relist cria um novo leilão com as mesmas regras deste, ligado a ele por
+// RelistedFromId e ao primeiro leilão da cadeia por OriginalAuctionId. Só
+// leilões encerrados podem ser relistados. Sem opções de horário o novo
+// leilão começa agora e dura o mesmo que o original, sem contar as pausas.
+func (au *Auction) Relist(options ...AuctionOption) (*Auction, *internal_error.InternalError) {
+	if !au.IsFinished() {
+		return nil, internal_error.NewBadRequestError("Only finished auctions can be relisted")
+	}
+
+	now := time.Now()
+	relisted := *au
+	relisted.Id = uuid.New().String()
+	relisted.Status = Active
+	relisted.Timestamp = now
+	relisted.StartsAt = now
+	relisted.EndsAt = time.Time{}
+	relisted.CurrentPrice = 0
+	relisted.RelistedFromId = au.Id
+	relisted.OriginalAuctionId = au.originalAuctionId()
+	relisted.RelistCount = au.RelistCount + 1
+	relisted.Version = 1
+	relisted.Clarifications = nil
+	relisted.PausedAt = time.Time{}
+	relisted.PausedDuration = 0
+	relisted.IncrementTiers = append([]IncrementTier(nil), au.IncrementTiers...)
+
+	if err := relisted.applyOptions(now, options); err != nil {
+		return nil, err
+	}
+
+	if relisted.EndsAt.IsZero() && !au.EndsAt.IsZero() {
+		relisted.EndsAt = relisted.StartsAt.Add(au.EndsAt.Sub(au.StartsAt) - au.PausedDuration)
+	}
+
+	return &relisted, nil
+}
+
+// CanAutoRelist indica se o leilão ainda pode ser relistado automaticamente
+func (au *Auction) CanAutoRelist() bool {
+	return au.RelistCount < au.MaxAutoRelists
+}
+
+// AutoRelist relista o leilão encerrado sem lances com a redução de preços
+// configurada aplicada ao preço inicial, à reserva e à compra imediata
+func (au *Auction) AutoRelist() (*Auction, *internal_error.InternalError) {
+	if !au.CanAutoRelist() {
+		return nil, internal_error.NewBadRequestError("Auction reached its automatic relist limit")
+	}
+
+	relisted, err := au.Relist()
+	if err != nil {
+		return nil, err
+	}
+
+	if au.RelistPriceReduction > 0 {
+		factor := 1 - au.RelistPriceReduction/100
+		relisted.StartingPrice = roundPrice(relisted.StartingPrice * factor)
+		relisted.ReservePrice = roundPrice(relisted.ReservePrice * factor)
+		relisted.BuyNowPrice = roundPrice(relisted.BuyNowPrice * factor)
+		if relisted.Type == Dutch {
+			relisted.CurrentPrice = relisted.StartingPrice
+		}
+
+		if err := relisted.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return relisted, nil
+}
+
+// originalAuctionId é o primeiro leilão da cadeia de relistagens
+func (au *Auction) originalAuctionId() string {
+	if au.OriginalAuctionId != "" {
+		return au.OriginalAuctionId
+	}
+
+	return au.Id
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
+
+// applyOptions aplica as opções de criação, deriva o status inicial e o
+// preço publicado do leilão holandês e valida o resultado
+func (au *Auction) applyOptions(now time.Time, options []AuctionOption) *internal_error.InternalError {
+	for _, option := range options {
+		option(au)
+	}
+
+	sort.Slice(au.IncrementTiers, func(i, j int) bool {
+		return au.IncrementTiers[i].From < au.IncrementTiers[j].From
+	})
+
+	if au.StartsAt.After(now) {
+		au.Status = Scheduled
+	}
+
+	if au.Type == Dutch {
+		au.CurrentPrice = au.StartingPrice
+	}
+
+	return au.Validate()
+}
+
+// auctionTransitions lista as mudanças de status permitidas. Leilões
+// encerrados, sem venda e cancelados são finais; o encerrado só passa a
+// sem venda na apuração da reserva.
+var auctionTransitions = map[AuctionStatus][]AuctionStatus{
+	Draft:            {Active, Scheduled, Cancelled},
+	Scheduled:        {Active, Cancelled},
+	Active:           {Completed, Cancelled, Paused},
+	Paused:           {Active, Cancelled},
+	Completed:        {EndedWithoutSale},
+	EndedWithoutSale: {},
+	Cancelled:        {},
+}
+
+// CanTransitionTo indica se o leilão pode passar do status atual ao informado
+func (au *Auction) CanTransitionTo(status AuctionStatus) bool {
+	for _, allowed := range auctionTransitions[au.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateTransition retorna um bad_request quando a mudança de status não é
+// permitida
+func (au *Auction) ValidateTransition(status AuctionStatus) *internal_error.InternalError {
+	if au.CanTransitionTo(status) {
+		return nil
+	}
+
+	return internal_error.NewBadRequestError(
+		fmt.Sprintf("Auction cannot change from status %d to %d", au.Status, status))
+}
+
+// Pause congela um leilão ativo a partir de now
+func (au *Auction) Pause(now time.Time) *internal_error.InternalError {
+	if err := au.ValidateTransition(Paused); err != nil {
+		return err
+	}
+
+	if !au.EndsAt.IsZero() && !now.Before(au.EndsAt) {
+		return internal_error.NewBadRequestError("Auction has already ended")
+	}
+
+	au.Status = Paused
+	au.PausedAt = now
+
+	return nil
+}
+
+// Resume reabre um leilão pausado, adiando o término pelo tempo que ele ficou
+// em pausa. Nos leilões holandeses o preço volta a cair de onde parou.
+func (au *Auction) Resume(now time.Time) *internal_error.InternalError {
+	if au.Status != Paused {
+		return internal_error.NewBadRequestError("Only paused auctions can be resumed")
+	}
+
+	pausedFor := now.Sub(au.PausedAt)
+	if pausedFor < 0 {
+		pausedFor = 0
+	}
+
+	if !au.EndsAt.IsZero() {
+		au.EndsAt = au.EndsAt.Add(pausedFor)
+	}
+
+	au.Status = Active
+	au.PausedAt = time.Time{}
+	au.PausedDuration += pausedFor
+
+	return nil
+}
+
+// Edit aplica a edição a um leilão ainda aberto e incrementa a versão; o
+// leilão só é alterado se o resultado for válido. Com lances (hasBids) só é
+// possível acrescentar esclarecimentos à descrição.
+func (au *Auction) Edit(edit AuctionEdit, hasBids bool, now time.Time) *internal_error.InternalError {
+	if au.IsFinished() {
+		return internal_error.NewBadRequestError("Finished auctions cannot be edited")
+	}
+
+	if !edit.changesLockedFields() && edit.Clarification == "" {
+		return internal_error.NewBadRequestError("No changes informed")
+	}
+
+	if hasBids && edit.changesLockedFields() {
+		return internal_error.NewBadRequestError("Only description clarifications can be added after the first bid",
+			internal_error.Causes{Field: "clarification", Message: "is the only field editable after the first bid"})
+	}
+
+	edited := *au
+	if edit.Description != nil {
+		edited.Description = *edit.Description
+	}
+	if edit.Category != nil {
+		edited.Category = *edit.Category
+	}
+	if edit.StartingPrice != nil {
+		edited.StartingPrice = *edit.StartingPrice
+	}
+	if edit.ReservePrice != nil {
+		edited.ReservePrice = *edit.ReservePrice
+	}
+	if edit.MinIncrement != nil {
+		edited.MinIncrement = *edit.MinIncrement
+	}
+	if edit.IncrementTiers != nil {
+		edited.IncrementTiers = append([]IncrementTier(nil), *edit.IncrementTiers...)
+		sort.Slice(edited.IncrementTiers, func(i, j int) bool {
+			return edited.IncrementTiers[i].From < edited.IncrementTiers[j].From
+		})
+	}
+	if edit.PricingRule != nil {
+		edited.PricingRule = *edit.PricingRule
+	}
+	if edit.BuyNowPrice != nil {
+		edited.BuyNowPrice = *edit.BuyNowPrice
+	}
+
+	if edit.Clarification != "" {
+		edited.Clarifications = append(append([]Clarification(nil), edited.Clarifications...),
+			Clarification{Text: edit.Clarification, CreatedAt: now})
+	}
+
+	if edited.Type == Dutch && edited.Status != Draft {
+		edited.CurrentPrice = edited.PriceAt(now)
+	}
+
+	edited.Version++
+
+	// Rascunhos podem continuar incompletos até a publicação
+	if edited.Status != Draft {
+		if err := edited.Validate(); err != nil {
+			return err
+		}
+	}
+
+	*au = edited
+
+	return nil
+}
+
+// IsFinished indica se o leilão não recebe mais lances nem muda de status,
+// exceto pela apuração da reserva
+func (au *Auction) IsFinished() bool {
+	return au.Status == Completed || au.Status == EndedWithoutSale || au.Status == Cancelled
+}
+
 func (au *Auction) Validate() *internal_error.InternalError {
 	if len(au.ProductName) <= 1 ||
 		len(au.Category) <= 2 ||
@@ -37,25 +457,399 @@ func (au *Auction) Validate() *internal_error.InternalError {
 		return internal_error.NewBadRequestError("invalid auction object")
 	}
 
+	if au.Type != English && au.Type != SealedFirstPrice && au.Type != SealedSecondPrice && au.Type != Dutch &&
+		au.Type != Reverse {
+		return internal_error.NewBadRequestError("invalid auction type")
+	}
+
+	if au.ReservePrice < 0 {
+		return internal_error.NewBadRequestError("auction reserve price must not be negative")
+	}
+
+	if au.StartingPrice < 0 || au.MinIncrement < 0 {
+		return internal_error.NewBadRequestError("auction starting price and increment must not be negative")
+	}
+
+	for _, tier := range au.IncrementTiers {
+		if tier.From < 0 || tier.Increment <= 0 {
+			return internal_error.NewBadRequestError("auction increment tiers must have a positive increment")
+		}
+	}
+
+	if au.Quantity < 1 || au.PricingRule != PayAsBid && au.PricingRule != UniformPrice {
+		return internal_error.NewBadRequestError("invalid auction quantity or pricing rule")
+	}
+
+	if au.IsMultiUnit() && au.Type != English && au.Type != SealedFirstPrice {
+		return internal_error.NewBadRequestError("multi-unit auctions must be english or sealed first-price")
+	}
+
+	if au.BuyNowPrice < 0 {
+		return internal_error.NewBadRequestError("auction buy-it-now price must not be negative")
+	}
+
+	if au.BuyNowPrice > 0 {
+		if au.Type != English || au.IsMultiUnit() {
+			return internal_error.NewBadRequestError("buy-it-now is only available for single-unit english auctions")
+		}
+
+		if au.BuyNowPrice < au.StartingPrice || au.BuyNowPrice < au.ReservePrice {
+			return internal_error.NewBadRequestError(
+				"auction buy-it-now price must not be below the starting and reserve prices")
+		}
+	}
+
+	if au.MaxAutoRelists < 0 || au.RelistPriceReduction < 0 || au.RelistPriceReduction >= 100 {
+		return internal_error.NewBadRequestError("invalid auction automatic relist settings")
+	}
+
+	if au.RelistPriceReduction > 0 && au.Type == Reverse {
+		return internal_error.NewBadRequestError("reverse auctions cannot reduce prices when relisted")
+	}
+
+	if au.Type == Dutch {
+		if au.StartingPrice <= 0 || au.PriceDecrement <= 0 || au.PriceDropInterval < time.Second {
+			return internal_error.NewBadRequestError(
+				"dutch auctions need a starting price, a price decrement and a price drop interval of at least 1s")
+		}
+
+		if au.ReservePrice >= au.StartingPrice {
+			return internal_error.NewBadRequestError("dutch auction reserve price must be below the starting price")
+		}
+	}
+
+	if !au.EndsAt.IsZero() {
+		if !au.EndsAt.After(au.StartsAt) {
+			return internal_error.NewBadRequestError("auction end time must be after its start time")
+		}
+
+		if !au.EndsAt.After(time.Now()) {
+			return internal_error.NewBadRequestError("auction end time must be in the future")
+		}
+	}
+
 	return nil
 }
 
+// IsReserveMet indica se o valor atinge o preço de reserva do leilão. No
+// reverso a reserva é o máximo que o comprador aceita pagar.
+func (au *Auction) IsReserveMet(amount float64) bool {
+	if au.IsReverse() {
+		return au.ReservePrice <= 0 || amount <= au.ReservePrice
+	}
+
+	return amount >= au.ReservePrice
+}
+
+// MinimumIncrement retorna o incremento exigido a partir do preço atual,
+// usando a maior faixa que começa até esse preço
+func (au *Auction) MinimumIncrement(currentPrice float64) float64 {
+	increment := au.MinIncrement
+	for _, tier := range au.IncrementTiers {
+		if tier.From > currentPrice {
+			break
+		}
+		increment = tier.Increment
+	}
+
+	return increment
+}
+
+// IsReverse indica se é um leilão de compra, em que os fornecedores dão
+// lances decrescentes e vence o menor
+func (au *Auction) IsReverse() bool {
+	return au.Type == Reverse
+}
+
+// IsMultiUnit indica se o leilão oferece várias unidades. Nesses leilões cada
+// lance informa a quantidade, vencem os maiores lances até acabar a oferta e
+// os lances só precisam respeitar o preço inicial.
+func (au *Auction) IsMultiUnit() bool {
+	return au.Quantity > 1
+}
+
+// AcceptsProxyBids indica se o leilão aceita lances automáticos, disponíveis
+// apenas no inglês de uma unidade
+func (au *Auction) AcceptsProxyBids() bool {
+	return au.Type == English && !au.IsMultiUnit()
+}
+
+// AcceptsBuyNow indica se o leilão tem preço de compra imediata
+func (au *Auction) AcceptsBuyNow() bool {
+	return au.BuyNowPrice > 0
+}
+
+// ValidateBidQuantity verifica se o lance não pede mais unidades do que o
+// leilão oferece
+func (au *Auction) ValidateBidQuantity(quantity int) *internal_error.InternalError {
+	if quantity <= au.Quantity {
+		return nil
+	}
+
+	return internal_error.NewBadRequestError("Bid quantity exceeds the units offered",
+		internal_error.Causes{Field: "quantity", Message: fmt.Sprintf("must be at most %d", au.Quantity)})
+}
+
+// IsSealed indica se os lances ficam ocultos até o fim do leilão. Nesses
+// leilões cada usuário tem um único lance, que pode ser substituído, e os
+// lances não precisam superar os demais.
+func (au *Auction) IsSealed() bool {
+	return au.Type == SealedFirstPrice || au.Type == SealedSecondPrice
+}
+
+// ClearingPrice retorna quanto o vencedor paga. No leilão de segundo preço
+// (Vickrey) é o segundo maior lance, com piso na reserva e no preço inicial;
+// nos demais é o próprio lance vencedor. runnerUpAmount zero indica que não
+// houve segundo lance.
+func (au *Auction) ClearingPrice(winningAmount, runnerUpAmount float64) float64 {
+	if au.Type != SealedSecondPrice {
+		return winningAmount
+	}
+
+	clearingPrice := math.Max(runnerUpAmount, math.Max(au.ReservePrice, au.StartingPrice))
+	return math.Min(clearingPrice, winningAmount)
+}
+
+// AcceptsBids indica se o leilão recebe lances; no holandês o único lance
+// possível é aceitar o preço atual
+func (au *Auction) AcceptsBids() bool {
+	return au.Type != Dutch
+}
+
+// PriceAt retorna o preço do leilão holandês no instante informado: o preço
+// inicial menos um decremento por intervalo completo desde o início, sem
+// contar as pausas. O preço para na última redução que o mantém positivo e
+// não abaixo da reserva.
+func (au *Auction) PriceAt(at time.Time) float64 {
+	return au.StartingPrice - float64(au.priceDrops(at))*au.PriceDecrement
+}
+
+// NextPriceDrop retorna quando o preço do leilão holandês cai de novo após o
+// instante informado; false quando ele já está no piso
+func (au *Auction) NextPriceDrop(at time.Time) (time.Time, bool) {
+	drops := au.priceDrops(at)
+	if au.Type != Dutch || drops >= au.maxPriceDrops() {
+		return time.Time{}, false
+	}
+
+	return au.priceClockStart().Add(time.Duration(drops+1) * au.PriceDropInterval), true
+}
+
+// priceClockStart é o início do relógio de preços, adiado pelo tempo já
+// passado em pausas
+func (au *Auction) priceClockStart() time.Time {
+	return au.StartsAt.Add(au.PausedDuration)
+}
+
+func (au *Auction) priceDrops(at time.Time) int64 {
+	if au.Status == Paused && at.After(au.PausedAt) {
+		at = au.PausedAt
+	}
+
+	if au.Type != Dutch || !at.After(au.priceClockStart()) {
+		return 0
+	}
+
+	drops := int64(at.Sub(au.priceClockStart()) / au.PriceDropInterval)
+	if maxDrops := au.maxPriceDrops(); drops > maxDrops {
+		return maxDrops
+	}
+
+	return drops
+}
+
+func (au *Auction) maxPriceDrops() int64 {
+	maxDrops := int64(math.Floor((au.StartingPrice - au.ReservePrice) / au.PriceDecrement))
+	if au.StartingPrice-float64(maxDrops)*au.PriceDecrement <= 0 {
+		maxDrops--
+	}
+
+	return maxDrops
+}
+
+// BidsAreVisible indica se os lances podem ser listados: sempre em leilões
+// abertos e, nos fechados (sealed), só depois do encerramento
+func (au *Auction) BidsAreVisible() bool {
+	return !au.IsSealed() || au.Status == Completed || au.Status == EndedWithoutSale
+}
+
+// MinimumBidAmount retorna o menor lance aceito dado o maior lance atual;
+// highestAmount zero indica que o leilão ainda não tem lances. Em leilões
+// fechados o maior lance é desconhecido e nos de várias unidades não há um
+// único maior lance, então vale sempre o preço inicial.
+func (au *Auction) MinimumBidAmount(highestAmount float64) float64 {
+	if highestAmount <= 0 || au.IsSealed() || au.IsMultiUnit() {
+		return au.StartingPrice
+	}
+
+	return highestAmount + au.MinimumIncrement(highestAmount)
+}
+
+// MaximumBidAmount retorna o maior lance aceito em um leilão reverso dado o
+// menor lance atual; lowestAmount zero indica que ainda não há lances e vale o
+// preço inicial, que é o teto do comprador (zero é sem teto)
+func (au *Auction) MaximumBidAmount(lowestAmount float64) float64 {
+	if lowestAmount <= 0 {
+		return au.StartingPrice
+	}
+
+	return lowestAmount - au.MinimumIncrement(lowestAmount)
+}
+
+// ValidateBidAmount valida o valor contra o melhor lance atual: o maior, ou o
+// menor nos leilões reversos
+func (au *Auction) ValidateBidAmount(amount, highestAmount float64) *internal_error.InternalError {
+	if au.IsReverse() {
+		return au.validateReverseBidAmount(amount, highestAmount)
+	}
+
+	if au.IsSealed() || au.IsMultiUnit() {
+		highestAmount = 0
+	}
+
+	minimumAmount := au.MinimumBidAmount(highestAmount)
+	if amount >= minimumAmount && amount > highestAmount {
+		return nil
+	}
+
+	message := fmt.Sprintf("must be at least %.2f", minimumAmount)
+	if highestAmount > 0 {
+		message = fmt.Sprintf("must beat the current highest bid of %.2f by at least %.2f",
+			highestAmount, au.MinimumIncrement(highestAmount))
+	}
+
+	return internal_error.NewBadRequestError("Bid amount is too low",
+		internal_error.Causes{Field: "amount", Message: message})
+}
+
+func (au *Auction) validateReverseBidAmount(amount, lowestAmount float64) *internal_error.InternalError {
+	maximumAmount := au.MaximumBidAmount(lowestAmount)
+	if lowestAmount <= 0 && (maximumAmount <= 0 || amount <= maximumAmount) ||
+		lowestAmount > 0 && amount <= maximumAmount && amount < lowestAmount {
+		return nil
+	}
+
+	message := fmt.Sprintf("must be at most %.2f", maximumAmount)
+	if lowestAmount > 0 {
+		message = fmt.Sprintf("must undercut the current lowest bid of %.2f by at least %.2f",
+			lowestAmount, au.MinimumIncrement(lowestAmount))
+	}
+
+	return internal_error.NewBadRequestError("Bid amount is too high",
+		internal_error.Causes{Field: "amount", Message: message})
+}
+
+type IncrementTier struct {
+	From      float64
+	Increment float64
+}
+
+// Auction é o leilão. CurrentPrice é o preço publicado pelo relógio de
+// preços nos leilões holandeses e só é usado por eles. PausedAt é o início
+// da pausa em andamento e PausedDuration o tempo já passado em pausas.
 type Auction struct {
-	Id          string
-	ProductName string
-	Category    string
-	Description string
-	Condition   ProductCondition
-	Status      AuctionStatus
-	Timestamp   time.Time
+	Id                string
+	ProductName       string
+	Category          string
+	Description       string
+	Condition         ProductCondition
+	Type              AuctionType
+	Status            AuctionStatus
+	Timestamp         time.Time
+	StartsAt          time.Time
+	EndsAt            time.Time
+	ReservePrice      float64
+	StartingPrice     float64
+	MinIncrement      float64
+	IncrementTiers    []IncrementTier
+	PriceDecrement    float64
+	PriceDropInterval time.Duration
+	CurrentPrice      float64
+	Quantity          int
+	PricingRule       PricingRule
+	BuyNowPrice       float64
+	RelistedFromId    string
+	PausedAt          time.Time
+	PausedDuration    time.Duration
+	// MaxAutoRelists e RelistPriceReduction (em %) configuram a relistagem
+	// automática; RelistCount conta as relistagens desde OriginalAuctionId
+	MaxAutoRelists       int
+	RelistPriceReduction float64
+	RelistCount          int
+	OriginalAuctionId    string
+	// Version conta as edições do leilão, a partir de 1 na criação
+	Version        int
+	Clarifications []Clarification
+	// DraftDuration é a duração pedida no rascunho, aplicada na publicação
+	DraftDuration time.Duration
+}
+
+// Clarification é um esclarecimento acrescentado à descrição do leilão; é a
+// única edição permitida depois do primeiro lance
+type Clarification struct {
+	Text      string
+	CreatedAt time.Time
+}
+
+// AuctionEdit traz as mudanças de uma edição do leilão; campos nil mantêm o
+// valor atual e Clarification, quando informado, é acrescentado à descrição
+type AuctionEdit struct {
+	Description    *string
+	Category       *string
+	StartingPrice  *float64
+	ReservePrice   *float64
+	MinIncrement   *float64
+	IncrementTiers *[]IncrementTier
+	PricingRule    *PricingRule
+	BuyNowPrice    *float64
+	Clarification  string
+}
+
+// changesLockedFields indica se a edição muda campos que ficam travados
+// depois do primeiro lance
+func (e AuctionEdit) changesLockedFields() bool {
+	return e.Description != nil || e.Category != nil || e.StartingPrice != nil ||
+		e.ReservePrice != nil || e.MinIncrement != nil || e.IncrementTiers != nil ||
+		e.PricingRule != nil || e.BuyNowPrice != nil
+}
+
+// AuctionRevision é uma versão anterior do leilão, guardada a cada edição
+type AuctionRevision struct {
+	AuctionId  string
+	Version    int
+	Auction    Auction
+	ReplacedAt time.Time
 }
 
 type ProductCondition int
+type AuctionType int
 type AuctionStatus int
+type PricingRule int
+
+const (
+	English AuctionType = iota
+	SealedFirstPrice
+	SealedSecondPrice
+	Dutch
+	Reverse
+)
+
+const (
+	PayAsBid PricingRule = iota
+	UniformPrice
+)
 
 const (
 	Active AuctionStatus = iota
 	Completed
+	Scheduled
+	EndedWithoutSale
+	Cancelled
+	// Paused congela o leilão: lances são recusados e o término não corre
+	Paused
+	// Draft é o rascunho do vendedor: pode estar incompleto e só entra no ar
+	// (e começa a contar o tempo) ao ser publicado
+	Draft
 )
 
 const (
@@ -76,4 +870,48 @@ type AuctionRepositoryInterface interface {
 
 	FindAuctionById(
 		ctx context.Context, id string) (*Auction, *internal_error.InternalError)
+
+	UpdateAuctionStatus(
+		ctx context.Context,
+		auctionId string,
+		status AuctionStatus) *internal_error.InternalError
+
+	// ChangeAuctionStatus muda o status apenas se o leilão ainda estiver em
+	// from, cancelando os timers e executando os handlers de fechamento
+	// quando o leilão deixa de receber lances
+	ChangeAuctionStatus(
+		ctx context.Context,
+		auctionId string,
+		from, to AuctionStatus) *internal_error.InternalError
+
+	// PauseAuction grava a pausa de um leilão ativo e suspende o seu
+	// fechamento e o relógio de preços
+	PauseAuction(
+		ctx context.Context,
+		auctionEntity *Auction) *internal_error.InternalError
+
+	// ResumeAuction grava a retomada de um leilão pausado desde pausedAt,
+	// com o término já adiado, e reagenda o seu fechamento
+	ResumeAuction(
+		ctx context.Context,
+		auctionEntity *Auction,
+		pausedAt time.Time) *internal_error.InternalError
+
+	// UpdateAuction grava a edição do leilão se ele ainda estiver na versão
+	// de previous, guardando previous como revisão
+	UpdateAuction(
+		ctx context.Context,
+		auctionEntity *Auction,
+		previous *Auction) *internal_error.InternalError
+
+	// FindAuctionRevisions lista as versões anteriores do leilão, da mais
+	// antiga para a mais recente
+	FindAuctionRevisions(
+		ctx context.Context, auctionId string) ([]AuctionRevision, *internal_error.InternalError)
+
+	// PublishAuction grava o rascunho publicado, se ele ainda for um
+	// rascunho, e inicia o relógio do leilão
+	PublishAuction(
+		ctx context.Context,
+		auctionEntity *Auction) *internal_error.InternalError
 }
@@ -0,0 +1,573 @@
+package auction_entity
+
+import (
+	"testing"
+	"time"
+)
+
+// Teste das regras de início e término informados na criação do leilão
+func TestCreateAuctionSchedule(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		options     []AuctionOption
+		valid       bool
+		description string
+	}{
+		{nil, true, "sem horários (usa duração padrão)"},
+		{[]AuctionOption{WithEndsAt(now.Add(time.Hour))}, true, "término em 1 hora"},
+		{[]AuctionOption{WithStartsAt(now.Add(time.Hour)), WithEndsAt(now.Add(2 * time.Hour))}, true, "início futuro"},
+		{[]AuctionOption{WithStartsAt(now.Add(time.Hour)), WithEndsAt(now.Add(30 * time.Minute))}, false, "término antes do início"},
+		{[]AuctionOption{WithStartsAt(now.Add(-2 * time.Hour)), WithEndsAt(now.Add(-time.Hour))}, false, "término no passado"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New, tc.options...)
+
+			if tc.valid && err != nil {
+				t.Fatalf("Leilão deveria ser válido, mas retornou erro: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("Leilão deveria ser inválido, mas foi criado: %+v", auction)
+			}
+		})
+	}
+}
+
+// Teste do status inicial conforme o horário de início
+func TestCreateAuctionInitialStatus(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New)
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+	if auction.Status != Active {
+		t.Errorf("Leilão sem início futuro deveria estar ativo, mas status é: %v", auction.Status)
+	}
+
+	auction, err = CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithStartsAt(time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+	if auction.Status != Scheduled {
+		t.Errorf("Leilão com início futuro deveria estar agendado, mas status é: %v", auction.Status)
+	}
+}
+
+// Teste do preço inicial e dos incrementos mínimos absolutos e por faixa
+func TestValidateBidAmount(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithStartingPrice(50),
+		WithMinIncrement(1),
+		WithIncrementTiers([]IncrementTier{
+			{From: 1000, Increment: 25},
+			{From: 100, Increment: 5},
+		}))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	testCases := []struct {
+		amount        float64
+		highestAmount float64
+		valid         bool
+		description   string
+	}{
+		{49.99, 0, false, "primeiro lance abaixo do preço inicial"},
+		{50, 0, true, "primeiro lance igual ao preço inicial"},
+		{60.5, 60, false, "incremento abaixo do mínimo absoluto"},
+		{61, 60, true, "incremento igual ao mínimo absoluto"},
+		{104, 100, false, "incremento abaixo da faixa a partir de 100"},
+		{105, 100, true, "incremento igual à faixa a partir de 100"},
+		{1020, 1000, false, "incremento abaixo da faixa a partir de 1000"},
+		{1025, 1000, true, "incremento igual à faixa a partir de 1000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := auction.ValidateBidAmount(tc.amount, tc.highestAmount)
+
+			if tc.valid && err != nil {
+				t.Errorf("Lance de %.2f deveria ser aceito, mas retornou erro: %v", tc.amount, err)
+			}
+			if !tc.valid {
+				if err == nil {
+					t.Fatalf("Lance de %.2f deveria ser recusado", tc.amount)
+				}
+				if len(err.Causes) != 1 || err.Causes[0].Field != "amount" {
+					t.Errorf("Erro deveria apontar o campo amount, causas: %+v", err.Causes)
+				}
+			}
+		})
+	}
+}
+
+// Teste de lances sem incremento configurado: basta superar o maior lance
+func TestValidateBidAmountWithoutIncrement(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New)
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	if err := auction.ValidateBidAmount(100, 100); err == nil {
+		t.Errorf("Lance igual ao maior lance deveria ser recusado")
+	}
+	if err := auction.ValidateBidAmount(100.01, 100); err != nil {
+		t.Errorf("Lance acima do maior lance deveria ser aceito, mas retornou erro: %v", err)
+	}
+}
+
+// Teste dos leilões fechados: os lances só precisam respeitar o preço
+// inicial e ficam ocultos até o encerramento
+func TestSealedAuctionRules(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithAuctionType(SealedFirstPrice),
+		WithStartingPrice(50),
+		WithMinIncrement(10))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	if err := auction.ValidateBidAmount(60, 500); err != nil {
+		t.Errorf("Lance fechado não deveria depender do maior lance, erro: %v", err)
+	}
+	if err := auction.ValidateBidAmount(49, 0); err == nil {
+		t.Errorf("Lance fechado abaixo do preço inicial deveria ser recusado")
+	}
+
+	if auction.BidsAreVisible() {
+		t.Errorf("Lances de leilão fechado ativo não deveriam ser visíveis")
+	}
+	auction.Status = Completed
+	if !auction.BidsAreVisible() {
+		t.Errorf("Lances de leilão fechado encerrado deveriam ser visíveis")
+	}
+
+	if _, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithAuctionType(AuctionType(99))); err == nil {
+		t.Errorf("Tipo de leilão inválido deveria ser recusado")
+	}
+}
+
+// Teste do leilão holandês: o preço cai um decremento por intervalo completo
+// e para no piso (reserva ou último valor positivo)
+func TestDutchAuctionPrice(t *testing.T) {
+	startsAt := time.Now().Add(time.Hour)
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithAuctionType(Dutch),
+		WithStartsAt(startsAt),
+		WithStartingPrice(100),
+		WithReservePrice(65),
+		WithPriceDrop(10, time.Minute))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	if auction.CurrentPrice != 100 || auction.AcceptsBids() {
+		t.Errorf("Leilão holandês deveria publicar o preço inicial e recusar lances, obtido: %.2f", auction.CurrentPrice)
+	}
+
+	testCases := []struct {
+		elapsed     time.Duration
+		price       float64
+		hasNextDrop bool
+		description string
+	}{
+		{-time.Minute, 100, true, "antes do início"},
+		{59 * time.Second, 100, true, "intervalo incompleto"},
+		{2 * time.Minute, 80, true, "duas reduções"},
+		{3 * time.Minute, 70, false, "última redução acima da reserva"},
+		{time.Hour, 70, false, "preço parado no piso"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			at := startsAt.Add(tc.elapsed)
+			if price := auction.PriceAt(at); price != tc.price {
+				t.Errorf("Preço esperado: %.2f, obtido: %.2f", tc.price, price)
+			}
+
+			nextDrop, ok := auction.NextPriceDrop(at)
+			if ok != tc.hasNextDrop || ok && !nextDrop.After(at) {
+				t.Errorf("Próxima redução inesperada: %v (%v)", nextDrop, ok)
+			}
+		})
+	}
+
+	withoutReserve, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithAuctionType(Dutch),
+		WithStartsAt(startsAt),
+		WithStartingPrice(30),
+		WithPriceDrop(10, time.Minute))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+	if price := withoutReserve.PriceAt(startsAt.Add(time.Hour)); price != 10 {
+		t.Errorf("Preço sem reserva deveria parar no último valor positivo, obtido: %.2f", price)
+	}
+
+	if _, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithAuctionType(Dutch),
+		WithStartingPrice(100)); err == nil {
+		t.Errorf("Leilão holandês sem decremento deveria ser recusado")
+	}
+}
+
+// Teste do leilão reverso: os lances precisam ficar abaixo do teto e do
+// menor lance pelo incremento, e a reserva é o máximo aceito pelo comprador
+func TestReverseAuctionRules(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithAuctionType(Reverse),
+		WithStartingPrice(1000),
+		WithReservePrice(800),
+		WithMinIncrement(10))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	testCases := []struct {
+		amount       float64
+		lowestAmount float64
+		shouldPass   bool
+		description  string
+	}{
+		{1000, 0, true, "primeiro lance no teto"},
+		{1001, 0, false, "primeiro lance acima do teto"},
+		{890, 900, true, "cobre o menor lance pelo incremento"},
+		{895, 900, false, "abaixo do menor lance sem o incremento"},
+		{950, 900, false, "acima do menor lance"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := auction.ValidateBidAmount(tc.amount, tc.lowestAmount)
+			if tc.shouldPass && err != nil {
+				t.Errorf("Lance de %.2f deveria ser aceito, mas retornou erro: %v", tc.amount, err)
+			}
+			if !tc.shouldPass && err == nil {
+				t.Errorf("Lance de %.2f deveria ser recusado", tc.amount)
+			}
+		})
+	}
+
+	if !auction.IsReserveMet(800) || auction.IsReserveMet(801) {
+		t.Errorf("Reserva do leilão reverso deveria aceitar apenas valores até 800")
+	}
+}
+
+// Teste do leilão de várias unidades: a quantidade do lance é limitada pela
+// oferta e os lances só precisam respeitar o preço inicial
+func TestMultiUnitAuctionRules(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithQuantity(10),
+		WithStartingPrice(50),
+		WithMinIncrement(10))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	if err := auction.ValidateBidQuantity(10); err != nil {
+		t.Errorf("Lance com toda a oferta deveria ser aceito, erro: %v", err)
+	}
+	if err := auction.ValidateBidQuantity(11); err == nil {
+		t.Errorf("Lance acima da oferta deveria ser recusado")
+	}
+	if err := auction.ValidateBidAmount(60, 500); err != nil {
+		t.Errorf("Lance de várias unidades não deveria depender do maior lance, erro: %v", err)
+	}
+	if auction.AcceptsProxyBids() {
+		t.Errorf("Leilão de várias unidades não deveria aceitar lances automáticos")
+	}
+
+	if _, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithQuantity(10),
+		WithAuctionType(Reverse)); err == nil {
+		t.Errorf("Leilão reverso de várias unidades deveria ser recusado")
+	}
+}
+
+// Teste do preço de compra imediata: só no inglês de uma unidade e sem ficar
+// abaixo da reserva
+func TestBuyNowPriceValidation(t *testing.T) {
+	testCases := []struct {
+		options     []AuctionOption
+		shouldPass  bool
+		description string
+	}{
+		{[]AuctionOption{WithBuyNowPrice(500)}, true, "leilão inglês"},
+		{[]AuctionOption{WithBuyNowPrice(500), WithReservePrice(600)}, false, "abaixo da reserva"},
+		{[]AuctionOption{WithBuyNowPrice(500), WithAuctionType(SealedFirstPrice)}, false, "leilão fechado"},
+		{[]AuctionOption{WithBuyNowPrice(500), WithQuantity(3)}, false, "várias unidades"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New, tc.options...)
+			if tc.shouldPass && (err != nil || !auction.AcceptsBuyNow()) {
+				t.Errorf("Leilão deveria aceitar compra imediata, erro: %v", err)
+			}
+			if !tc.shouldPass && err == nil {
+				t.Errorf("Leilão deveria ser recusado")
+			}
+		})
+	}
+}
+
+func TestAuctionStatusTransitions(t *testing.T) {
+	testCases := []struct {
+		from        AuctionStatus
+		to          AuctionStatus
+		allowed     bool
+		description string
+	}{
+		{Scheduled, Active, true, "agendado abre"},
+		{Scheduled, Cancelled, true, "agendado é cancelado"},
+		{Active, Completed, true, "ativo é encerrado"},
+		{Active, Cancelled, true, "ativo é cancelado"},
+		{Completed, EndedWithoutSale, true, "encerrado sem atingir a reserva"},
+		{Completed, Cancelled, false, "encerrado não é cancelado"},
+		{Cancelled, Active, false, "cancelado não reabre"},
+		{EndedWithoutSale, Active, false, "sem venda não reabre"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auction := &Auction{Status: tc.from}
+			if auction.CanTransitionTo(tc.to) != tc.allowed {
+				t.Errorf("Transição de %d para %d deveria ser %v", tc.from, tc.to, tc.allowed)
+			}
+			if err := auction.ValidateTransition(tc.to); (err == nil) != tc.allowed {
+				t.Errorf("ValidateTransition de %d para %d retornou %v", tc.from, tc.to, err)
+			}
+		})
+	}
+}
+
+func TestRelistAuction(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithEndsAt(time.Now().Add(time.Hour)),
+		WithStartingPrice(100),
+		WithIncrementTiers([]IncrementTier{{From: 0, Increment: 5}}))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	if _, err := auction.Relist(); err == nil {
+		t.Error("Leilão ativo não deveria ser relistado")
+	}
+
+	auction.Status = EndedWithoutSale
+	relisted, err := auction.Relist()
+	if err != nil {
+		t.Fatalf("Erro ao relistar leilão: %v", err)
+	}
+
+	if relisted.Id == auction.Id || relisted.RelistedFromId != auction.Id {
+		t.Errorf("Leilão relistado deveria ter novo id ligado ao original")
+	}
+	if relisted.Status != Active || relisted.StartingPrice != 100 || len(relisted.IncrementTiers) != 1 {
+		t.Errorf("Leilão relistado deveria manter as regras do original: %+v", relisted)
+	}
+	if duration := relisted.EndsAt.Sub(relisted.StartsAt); duration.Round(time.Second) != time.Hour {
+		t.Errorf("Leilão relistado deveria durar o mesmo que o original, duração: %v", duration)
+	}
+
+	relisted.IncrementTiers[0].Increment = 10
+	if auction.IncrementTiers[0].Increment != 5 {
+		t.Error("Faixas de incremento não deveriam ser compartilhadas com o original")
+	}
+
+	startsAt := time.Now().Add(time.Hour)
+	scheduled, err := auction.Relist(WithStartsAt(startsAt), WithEndsAt(startsAt.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Erro ao relistar leilão agendado: %v", err)
+	}
+	if scheduled.Status != Scheduled {
+		t.Errorf("Leilão relistado com início futuro deveria ficar agendado, status: %d", scheduled.Status)
+	}
+}
+
+func TestPauseAndResumeAuction(t *testing.T) {
+	start := time.Now()
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithStartsAt(start),
+		WithEndsAt(start.Add(time.Hour)),
+		WithAuctionType(Dutch),
+		WithStartingPrice(100),
+		WithPriceDrop(1, time.Minute))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	if err := auction.Resume(start); err == nil {
+		t.Error("Leilão ativo não deveria ser retomado")
+	}
+
+	pausedAt := start.Add(10 * time.Minute)
+	if err := auction.Pause(pausedAt); err != nil {
+		t.Fatalf("Erro ao pausar leilão: %v", err)
+	}
+	if err := auction.Pause(pausedAt); err == nil {
+		t.Error("Leilão pausado não deveria ser pausado de novo")
+	}
+
+	resumedAt := pausedAt.Add(30 * time.Minute)
+	if price := auction.PriceAt(resumedAt); price != 90 {
+		t.Errorf("Preço deveria ficar congelado na pausa em 90, preço: %.2f", price)
+	}
+
+	if err := auction.Resume(resumedAt); err != nil {
+		t.Fatalf("Erro ao retomar leilão: %v", err)
+	}
+
+	if auction.Status != Active {
+		t.Errorf("Leilão retomado deveria estar ativo, status: %d", auction.Status)
+	}
+	if !auction.EndsAt.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("Término deveria ser adiado em 30 minutos, término: %v", auction.EndsAt)
+	}
+	if price := auction.PriceAt(resumedAt.Add(5 * time.Minute)); price != 85 {
+		t.Errorf("Preço deveria voltar a cair de onde parou, esperado 85, obtido: %.2f", price)
+	}
+}
+
+func TestAutoRelistAuction(t *testing.T) {
+	auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithEndsAt(time.Now().Add(time.Hour)),
+		WithStartingPrice(100),
+		WithReservePrice(200),
+		WithBuyNowPrice(300),
+		WithAutoRelist(2, 10))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	auction.Status = EndedWithoutSale
+	first, err := auction.AutoRelist()
+	if err != nil {
+		t.Fatalf("Erro ao relistar leilão: %v", err)
+	}
+	if first.StartingPrice != 90 || first.ReservePrice != 180 || first.BuyNowPrice != 270 {
+		t.Errorf("Preços deveriam cair 10%%: %.2f, %.2f, %.2f",
+			first.StartingPrice, first.ReservePrice, first.BuyNowPrice)
+	}
+	if first.RelistCount != 1 || first.OriginalAuctionId != auction.Id || first.RelistedFromId != auction.Id {
+		t.Errorf("Relistagem deveria ser ligada ao original: %+v", first)
+	}
+
+	first.Status = Completed
+	second, err := first.AutoRelist()
+	if err != nil {
+		t.Fatalf("Erro ao relistar leilão: %v", err)
+	}
+	if second.RelistCount != 2 || second.OriginalAuctionId != auction.Id || second.RelistedFromId != first.Id {
+		t.Errorf("Segunda relistagem deveria manter o original: %+v", second)
+	}
+	if second.StartingPrice != 81 {
+		t.Errorf("Preço inicial esperado: 81, obtido: %.2f", second.StartingPrice)
+	}
+
+	second.Status = Completed
+	if second.CanAutoRelist() {
+		t.Error("Leilão não deveria ser relistado além do limite")
+	}
+	if _, err := second.AutoRelist(); err == nil {
+		t.Error("AutoRelist deveria falhar além do limite")
+	}
+
+	if _, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+		WithAuctionType(Reverse), WithAutoRelist(1, 10)); err == nil {
+		t.Error("Leilão reverso não deveria reduzir preços na relistagem")
+	}
+}
+
+func TestEditAuction(t *testing.T) {
+	newAuction := func() *Auction {
+		auction, err := CreateAuction("Test Product", "Electronics", "Test Description", New,
+			WithEndsAt(time.Now().Add(time.Hour)),
+			WithStartingPrice(100))
+		if err != nil {
+			t.Fatalf("Erro ao criar leilão: %v", err)
+		}
+		return auction
+	}
+
+	description := "Updated description"
+	startingPrice := 150.0
+	buyNowPrice := 120.0
+
+	auction := newAuction()
+	if err := auction.Edit(AuctionEdit{Description: &description, StartingPrice: &startingPrice}, false, time.Now()); err != nil {
+		t.Fatalf("Erro ao editar leilão sem lances: %v", err)
+	}
+	if auction.Description != description || auction.StartingPrice != 150 || auction.Version != 2 {
+		t.Errorf("Edição não aplicada: %+v", auction)
+	}
+
+	if err := auction.Edit(AuctionEdit{BuyNowPrice: &buyNowPrice}, false, time.Now()); err == nil {
+		t.Error("Edição inválida deveria ser recusada")
+	}
+	if auction.BuyNowPrice != 0 || auction.Version != 2 {
+		t.Errorf("Edição recusada não deveria alterar o leilão: %+v", auction)
+	}
+
+	auction = newAuction()
+	if err := auction.Edit(AuctionEdit{StartingPrice: &startingPrice}, true, time.Now()); err == nil {
+		t.Error("Preço não deveria mudar depois do primeiro lance")
+	}
+	if err := auction.Edit(AuctionEdit{Clarification: "Includes the original box"}, true, time.Now()); err != nil {
+		t.Fatalf("Erro ao acrescentar esclarecimento: %v", err)
+	}
+	if len(auction.Clarifications) != 1 || auction.Description != "Test Description" || auction.Version != 2 {
+		t.Errorf("Esclarecimento deveria ser acrescentado sem mudar a descrição: %+v", auction)
+	}
+
+	if err := auction.Edit(AuctionEdit{}, true, time.Now()); err == nil {
+		t.Error("Edição vazia deveria ser recusada")
+	}
+
+	auction.Status = Cancelled
+	if err := auction.Edit(AuctionEdit{Clarification: "Too late"}, true, time.Now()); err == nil {
+		t.Error("Leilão encerrado não deveria ser editado")
+	}
+}
+
+// Teste do rascunho, que só é validado e começa a contar o tempo na publicação
+func TestPublishDraftAuction(t *testing.T) {
+	draft := CreateDraftAuction("", "Electronics", "", New, WithDuration(2*time.Hour))
+	if draft.Status != Draft || !draft.StartsAt.IsZero() || !draft.EndsAt.IsZero() {
+		t.Fatalf("Rascunho deveria ser criado sem horários: %+v", draft)
+	}
+
+	now := time.Now()
+	if err := draft.Publish(now); err == nil {
+		t.Error("Rascunho incompleto não deveria ser publicado")
+	}
+	if draft.Status != Draft || !draft.StartsAt.IsZero() {
+		t.Errorf("Publicação recusada não deveria alterar o rascunho: %+v", draft)
+	}
+
+	draft.ProductName = "Test Product"
+	draft.Description = "Test Description"
+	if err := draft.Publish(now); err != nil {
+		t.Fatalf("Erro ao publicar rascunho: %v", err)
+	}
+	if draft.Status != Active || !draft.StartsAt.Equal(now) || !draft.EndsAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("Duração deveria ser contada a partir da publicação: %+v", draft)
+	}
+
+	if err := draft.Publish(now); err == nil {
+		t.Error("Leilão publicado não deveria ser publicado de novo")
+	}
+
+	startsAt := now.Add(time.Hour)
+	scheduled := CreateDraftAuction("Test Product", "Electronics", "Test Description", New,
+		WithStartsAt(startsAt), WithDuration(time.Hour))
+	if err := scheduled.Publish(now); err != nil {
+		t.Fatalf("Erro ao publicar rascunho agendado: %v", err)
+	}
+	if scheduled.Status != Scheduled || !scheduled.EndsAt.Equal(startsAt.Add(time.Hour)) {
+		t.Errorf("Rascunho com início futuro deveria ser agendado: %+v", scheduled)
+	}
+}
@@ -7,21 +7,46 @@ import (
 	"time"
 )
 
+// Bid é um lance. Amount é o valor por unidade e Quantity quantas unidades o
+// lance disputa, sempre 1 fora dos leilões de várias unidades.
 type Bid struct {
-	Id        string
-	UserId    string
-	AuctionId string
-	Amount    float64
-	Timestamp time.Time
+	Id               string
+	UserId           string
+	AuctionId        string
+	Amount           float64
+	Quantity         int
+	Timestamp        time.Time
+	Status           BidStatus
+	RejectionReason  RejectionReason
+	RejectionMessage string
+}
+
+// BidOption configura atributos opcionais do lance na criação
+type BidOption func(*Bid)
+
+// WithQuantity define quantas unidades o lance disputa; o padrão é 1
+func WithQuantity(quantity int) BidOption {
+	return func(b *Bid) {
+		b.Quantity = quantity
+	}
 }
 
-func CreateBid(userId, auctionId string, amount float64) (*Bid, *internal_error.InternalError) {
+func CreateBid(
+	userId, auctionId string,
+	amount float64,
+	options ...BidOption) (*Bid, *internal_error.InternalError) {
 	bid := &Bid{
 		Id:        uuid.New().String(),
 		UserId:    userId,
 		AuctionId: auctionId,
 		Amount:    amount,
+		Quantity:  1,
 		Timestamp: time.Now(),
+		Status:    Queued,
+	}
+
+	for _, option := range options {
+		option(bid)
 	}
 
 	if err := bid.Validate(); err != nil {
@@ -38,19 +63,157 @@ func (b *Bid) Validate() *internal_error.InternalError {
 		return internal_error.NewBadRequestError("AuctionId is not a valid id")
 	} else if b.Amount <= 0 {
 		return internal_error.NewBadRequestError("Amount is not a valid value")
+	} else if b.Quantity <= 0 {
+		return internal_error.NewBadRequestError("Quantity is not a valid value")
 	}
 
 	return nil
 }
 
+// ProxyBid é o valor máximo que um usuário aceita pagar em um leilão. O
+// sistema dá lances automáticos em nome dele até esse teto, que nunca é exposto.
+type ProxyBid struct {
+	Id        string
+	UserId    string
+	AuctionId string
+	MaxAmount float64
+	Timestamp time.Time
+}
+
+func CreateProxyBid(userId, auctionId string, maxAmount float64) (*ProxyBid, *internal_error.InternalError) {
+	proxyBid := &ProxyBid{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		AuctionId: auctionId,
+		MaxAmount: maxAmount,
+		Timestamp: time.Now(),
+	}
+
+	if err := uuid.Validate(proxyBid.UserId); err != nil {
+		return nil, internal_error.NewBadRequestError("UserId is not a valid id")
+	} else if err := uuid.Validate(proxyBid.AuctionId); err != nil {
+		return nil, internal_error.NewBadRequestError("AuctionId is not a valid id")
+	} else if proxyBid.MaxAmount <= 0 {
+		return nil, internal_error.NewBadRequestError("MaxAmount is not a valid value")
+	}
+
+	return proxyBid, nil
+}
+
+type BidStatus int
+type RejectionReason string
+
+const (
+	Accepted BidStatus = iota
+	Rejected
+	Queued
+	// Replaced marca o lance substituído por um novo lance do mesmo usuário
+	// em leilões fechados (sealed)
+	Replaced
+	// Voided marca os lances de um leilão cancelado
+	Voided
+)
+
+const (
+	AuctionNotFound  RejectionReason = "auction_not_found"
+	AuctionClosed    RejectionReason = "auction_closed"
+	AuctionNotOpen   RejectionReason = "auction_not_open"
+	AuctionPaused    RejectionReason = "auction_paused"
+	BidTooLow        RejectionReason = "bid_too_low"
+	BidTooHigh       RejectionReason = "bid_too_high"
+	BidNotAllowed    RejectionReason = "bid_not_allowed"
+	InvalidQuantity  RejectionReason = "invalid_quantity"
+	ProcessingFailed RejectionReason = "processing_failed"
+)
+
+// BidResult é o resultado do processamento de um lance pelo repositório
+type BidResult struct {
+	BidId   string
+	Status  BidStatus
+	Reason  RejectionReason
+	Message string
+}
+
+func NewAcceptedBidResult(bidId string) BidResult {
+	return BidResult{
+		BidId:  bidId,
+		Status: Accepted,
+	}
+}
+
+func NewRejectedBidResult(bidId string, reason RejectionReason, message string) BidResult {
+	return BidResult{
+		BidId:   bidId,
+		Status:  Rejected,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
 type BidEntityRepository interface {
+	ValidateBid(
+		ctx context.Context,
+		bidEntity Bid) *internal_error.InternalError
+
 	CreateBid(
 		ctx context.Context,
-		bidEntities []Bid) *internal_error.InternalError
+		bidEntities []Bid) ([]BidResult, *internal_error.InternalError)
+
+	FindBidById(
+		ctx context.Context, bidId string) (*Bid, *internal_error.InternalError)
+
+	// QueueBid grava o lance enfileirado com status Queued; o lote depois
+	// grava o resultado no mesmo lance
+	QueueBid(
+		ctx context.Context,
+		bidEntity Bid) *internal_error.InternalError
+
+	// DiscardQueuedBid remove o lance gravado como Queued que não chegou a
+	// ser enfileirado
+	DiscardQueuedBid(
+		ctx context.Context, bidId string) *internal_error.InternalError
+
+	// CreateProxyBid registra (ou substitui) o teto do usuário no leilão,
+	// resolve os lances automáticos e retorna o maior lance resultante
+	CreateProxyBid(
+		ctx context.Context,
+		proxyBid ProxyBid) (*Bid, *internal_error.InternalError)
+
+	// AcceptAuctionPrice encerra o leilão holandês com o usuário como
+	// vencedor pelo preço atual e retorna o lance gravado
+	AcceptAuctionPrice(
+		ctx context.Context, auctionId, userId string) (*Bid, *internal_error.InternalError)
+
+	// BuyAuctionNow encerra o leilão com o usuário como vencedor pelo preço
+	// de compra imediata e retorna o lance gravado
+	BuyAuctionNow(
+		ctx context.Context, auctionId, userId string) (*Bid, *internal_error.InternalError)
+
+	// LockAuctionBids trava o processamento de lances do leilão até o unlock
+	// e informa se ele já recebeu lances, inclusive os ainda na fila
+	LockAuctionBids(
+		ctx context.Context, auctionId string) (bool, func(), *internal_error.InternalError)
+
+	// VoidBidsByAuctionId anula os lances e os tetos de um leilão cancelado
+	VoidBidsByAuctionId(
+		ctx context.Context, auctionId string) *internal_error.InternalError
 
 	FindBidByAuctionId(
 		ctx context.Context, auctionId string) ([]Bid, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context, auctionId string) (*Bid, *internal_error.InternalError)
+
+	FindTopBidsByAuctionId(
+		ctx context.Context, auctionId string, limit int64) ([]Bid, *internal_error.InternalError)
+}
+
+// BidWriteAheadLog registra em disco os lances enfileirados antes de
+// responder ao cliente, para que sobrevivam a uma queda antes da inserção em lote
+type BidWriteAheadLog interface {
+	Append(bid Bid) *internal_error.InternalError
+
+	Commit(bidIds []string) *internal_error.InternalError
+
+	Pending() ([]Bid, *internal_error.InternalError)
 }
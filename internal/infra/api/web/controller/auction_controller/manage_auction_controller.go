@@ -0,0 +1,98 @@
+package auction_controller
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+type changeStatusFunc func(
+	ctx context.Context, auctionId string) (*auction_usecase.AuctionOutputDTO, *internal_error.InternalError)
+
+func (u *AuctionController) EndAuction(c *gin.Context) {
+	changeStatus(c, u.auctionUseCase.EndAuction)
+}
+
+func (u *AuctionController) CancelAuction(c *gin.Context) {
+	changeStatus(c, u.auctionUseCase.CancelAuction)
+}
+
+func (u *AuctionController) PauseAuction(c *gin.Context) {
+	changeStatus(c, u.auctionUseCase.PauseAuction)
+}
+
+func (u *AuctionController) ResumeAuction(c *gin.Context) {
+	changeStatus(c, u.auctionUseCase.ResumeAuction)
+}
+
+func (u *AuctionController) PublishAuction(c *gin.Context) {
+	changeStatus(c, u.auctionUseCase.PublishAuction)
+}
+
+// RelistAuction aceita o corpo vazio, caso em que o novo leilão começa agora
+// com a duração do original
+func (u *AuctionController) RelistAuction(c *gin.Context) {
+	auctionId, ok := auctionIdParam(c)
+	if !ok {
+		return
+	}
+
+	var relistInputDTO auction_usecase.RelistAuctionInputDTO
+
+	if err := c.ShouldBindJSON(&relistInputDTO); err != nil && !errors.Is(err, io.EOF) {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	auctionOutput, err := u.auctionUseCase.RelistAuction(c.Request.Context(), auctionId, relistInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, auctionOutput)
+}
+
+func changeStatus(c *gin.Context, changeAuctionStatus changeStatusFunc) {
+	auctionId, ok := auctionIdParam(c)
+	if !ok {
+		return
+	}
+
+	auctionOutput, err := changeAuctionStatus(c.Request.Context(), auctionId)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionOutput)
+}
+
+func auctionIdParam(c *gin.Context) (string, bool) {
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return auctionId, true
+}
@@ -0,0 +1,51 @@
+package auction_controller
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type purchaseFunc func(
+	ctx context.Context,
+	auctionId string,
+	purchaseInput auction_usecase.PurchaseInputDTO) (*bid_usecase.BidOutputDTO, *internal_error.InternalError)
+
+func (u *AuctionController) AcceptPrice(c *gin.Context) {
+	purchase(c, u.auctionUseCase.AcceptPrice)
+}
+
+func (u *AuctionController) BuyNow(c *gin.Context) {
+	purchase(c, u.auctionUseCase.BuyNow)
+}
+
+func purchase(c *gin.Context, purchaseAuction purchaseFunc) {
+	auctionId, ok := auctionIdParam(c)
+	if !ok {
+		return
+	}
+
+	var purchaseInputDTO auction_usecase.PurchaseInputDTO
+
+	if err := c.ShouldBindJSON(&purchaseInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	bidOutput, err := purchaseAuction(c.Request.Context(), auctionId, purchaseInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, bidOutput)
+}
@@ -0,0 +1,62 @@
+package auction_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// UpdateAuction (PUT) substitui os campos editáveis do leilão
+func (u *AuctionController) UpdateAuction(c *gin.Context) {
+	u.updateAuction(c, true)
+}
+
+// PatchAuction (PATCH) altera apenas os campos informados
+func (u *AuctionController) PatchAuction(c *gin.Context) {
+	u.updateAuction(c, false)
+}
+
+func (u *AuctionController) FindAuctionRevisions(c *gin.Context) {
+	auctionId, ok := auctionIdParam(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := u.auctionUseCase.FindAuctionRevisions(c.Request.Context(), auctionId)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (u *AuctionController) updateAuction(c *gin.Context, replace bool) {
+	auctionId, ok := auctionIdParam(c)
+	if !ok {
+		return
+	}
+
+	var updateInputDTO auction_usecase.AuctionUpdateInputDTO
+
+	if err := c.ShouldBindJSON(&updateInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	auctionOutput, err := u.auctionUseCase.UpdateAuction(c.Request.Context(), auctionId, updateInputDTO, replace)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionOutput)
+}
@@ -1,7 +1,6 @@
 package bid_controller
 
 import (
-	"context"
 	"fullcycle-auction_go/configuration/rest_err"
 	"fullcycle-auction_go/internal/infra/api/web/validation"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
@@ -9,6 +8,10 @@ import (
 	"net/http"
 )
 
+// retryAfterSeconds é o valor do header Retry-After quando a fila de
+// lances está cheia
+const retryAfterSeconds = "5"
+
 type BidController struct {
 	bidUseCase bid_usecase.BidUseCaseInterface
 }
@@ -29,13 +32,22 @@ func (u *BidController) CreateBid(c *gin.Context) {
 		return
 	}
 
-	err := u.bidUseCase.CreateBid(context.Background(), bidInputDTO)
+	bidResult, err := u.bidUseCase.CreateBid(c.Request.Context(), bidInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 
+		if restErr.Code == http.StatusServiceUnavailable {
+			c.Header("Retry-After", retryAfterSeconds)
+		}
+
 		c.JSON(restErr.Code, restErr)
 		return
 	}
 
-	c.Status(http.StatusCreated)
+	if bidResult.Rejected() {
+		c.JSON(http.StatusOK, bidResult)
+		return
+	}
+
+	c.JSON(http.StatusCreated, bidResult)
 }
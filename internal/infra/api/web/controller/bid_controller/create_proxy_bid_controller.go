@@ -0,0 +1,30 @@
+package bid_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (u *BidController) CreateProxyBid(c *gin.Context) {
+	var proxyBidInputDTO bid_usecase.ProxyBidInputDTO
+
+	if err := c.ShouldBindJSON(&proxyBidInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	proxyBidOutput, err := u.bidUseCase.CreateProxyBid(c.Request.Context(), proxyBidInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, proxyBidOutput)
+}
@@ -1,7 +1,6 @@
 package bid_controller
 
 import (
-	"context"
 	"fullcycle-auction_go/configuration/rest_err"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -21,7 +20,7 @@ func (u *BidController) FindBidByAuctionId(c *gin.Context) {
 		return
 	}
 
-	bidOutputList, err := u.bidUseCase.FindBidByAuctionId(context.Background(), auctionId)
+	bidOutputList, err := u.bidUseCase.FindBidByAuctionId(c.Request.Context(), auctionId)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -30,3 +29,30 @@ func (u *BidController) FindBidByAuctionId(c *gin.Context) {
 
 	c.JSON(http.StatusOK, bidOutputList)
 }
+
+func (u *BidController) FindBidStatus(c *gin.Context) {
+	bidId := c.Param("bidId")
+
+	if err := uuid.Validate(bidId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "bidId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	bidStatus, err := u.bidUseCase.FindBidStatus(c.Request.Context(), bidId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, bidStatus)
+}
+
+func (u *BidController) FindBidQueueStats(c *gin.Context) {
+	c.JSON(http.StatusOK, u.bidUseCase.FindBidQueueStats(c.Request.Context()))
+}
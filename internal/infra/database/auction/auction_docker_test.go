@@ -349,3 +349,341 @@ func TestAuctionIntervalConfigurationWithMongoDB(t *testing.T) {
 		})
 	}
 }
+
+// Teste de recuperação dos leilões pendentes após reinício da aplicação
+func TestSchedulerRecoversAuctionsAfterRestartWithMongoDB(t *testing.T) {
+	// Conecta ao MongoDB de teste
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	// Simula leilões persistidos por uma instância anterior: um já vencido e
+	// outro que termina em 2 segundos
+	overdueAuction := AuctionEntityMongo{
+		Id:          "overdue-auction",
+		ProductName: "Test Product",
+		Category:    "Electronics",
+		Description: "Test Description",
+		Condition:   auction_entity.New,
+		Status:      auction_entity.Active,
+		Timestamp:   time.Now().Add(-time.Hour).Unix(),
+		EndsAt:      time.Now().Add(-time.Minute).Unix(),
+	}
+	pendingAuction := overdueAuction
+	pendingAuction.Id = "pending-auction"
+	pendingAuction.EndsAt = time.Now().Add(2 * time.Second).Unix()
+
+	if _, err := database.Collection("auctions").InsertMany(
+		ctx, []interface{}{overdueAuction, pendingAuction}); err != nil {
+		t.Fatalf("Erro ao inserir leilões: %v", err)
+	}
+
+	// Nova instância do repositório recupera os leilões no startup
+	repo := NewAuctionRepository(database)
+	if err := repo.Scheduler.Start(ctx); err != nil {
+		t.Fatalf("Erro ao iniciar agendador: %v", err)
+	}
+	defer repo.Scheduler.Stop()
+
+	time.Sleep(500 * time.Millisecond)
+
+	foundAuction, internalErr := repo.FindAuctionById(ctx, overdueAuction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão vencido: %v", internalErr)
+	}
+	if foundAuction.Status != auction_entity.Completed {
+		t.Errorf("Leilão vencido deveria ser fechado no startup, mas status é: %v", foundAuction.Status)
+	}
+
+	foundAuction, internalErr = repo.FindAuctionById(ctx, pendingAuction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão pendente: %v", internalErr)
+	}
+	if foundAuction.Status != auction_entity.Active {
+		t.Errorf("Leilão pendente deveria continuar ativo, mas status é: %v", foundAuction.Status)
+	}
+
+	// Aguarda o término reagendado
+	time.Sleep(3 * time.Second)
+
+	foundAuction, internalErr = repo.FindAuctionById(ctx, pendingAuction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão pendente após término: %v", internalErr)
+	}
+	if foundAuction.Status != auction_entity.Completed {
+		t.Errorf("Leilão pendente deveria ter sido fechado, mas status é: %v", foundAuction.Status)
+	}
+}
+
+// Teste do soft close: o término só é adiado dentro da janela final
+func TestExtendAuctionEndTimeWithMongoDB(t *testing.T) {
+	// Conecta ao MongoDB de teste
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	repo := NewAuctionRepository(database)
+	defer repo.Scheduler.Stop()
+
+	// Leilão que termina em 30 segundos
+	auction, internalErr := auction_entity.CreateAuction(
+		"Test Product",
+		"Electronics",
+		"Test Description",
+		auction_entity.New,
+		auction_entity.WithEndsAt(time.Now().Add(30*time.Second)),
+	)
+	if internalErr != nil {
+		t.Fatalf("Erro ao criar leilão: %v", internalErr)
+	}
+
+	if internalErr := repo.CreateAuction(ctx, auction); internalErr != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", internalErr)
+	}
+
+	// Fora da janela de 10 segundos: não estende
+	endsAt, internalErr := repo.ExtendAuctionEndTime(ctx, auction.Id, time.Now(), 10*time.Second, time.Minute)
+	if internalErr != nil {
+		t.Fatalf("Erro ao estender leilão: %v", internalErr)
+	}
+	if endsAt != nil {
+		t.Errorf("Leilão fora da janela não deveria ser estendido, novo término: %v", endsAt)
+	}
+
+	// Dentro da janela de 1 minuto: estende em 1 minuto
+	endsAt, internalErr = repo.ExtendAuctionEndTime(ctx, auction.Id, time.Now(), time.Minute, time.Minute)
+	if internalErr != nil {
+		t.Fatalf("Erro ao estender leilão: %v", internalErr)
+	}
+	if endsAt == nil {
+		t.Fatalf("Leilão dentro da janela deveria ser estendido")
+	}
+
+	expected := auction.EndsAt.Add(time.Minute).Unix()
+	if endsAt.Unix() != expected {
+		t.Errorf("Término esperado: %v, obtido: %v", time.Unix(expected, 0), endsAt)
+	}
+
+	foundAuction, internalErr := repo.FindAuctionById(ctx, auction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão: %v", internalErr)
+	}
+	if foundAuction.EndsAt.Unix() != expected {
+		t.Errorf("Término persistido esperado: %v, obtido: %v", time.Unix(expected, 0), foundAuction.EndsAt)
+	}
+
+	// A janela é avaliada no instante do lance, não no do processamento
+	bidTime := endsAt.Add(-5 * time.Second)
+	endsAt, internalErr = repo.ExtendAuctionEndTime(ctx, auction.Id, bidTime, 10*time.Second, time.Minute)
+	if internalErr != nil {
+		t.Fatalf("Erro ao estender leilão: %v", internalErr)
+	}
+	if endsAt == nil || endsAt.Unix() != expected+60 {
+		t.Errorf("Lance na janela final deveria estender o leilão, novo término: %v", endsAt)
+	}
+}
+
+// Teste de pausa e retomada: o leilão pausado não é fechado pelo timer e o
+// término é adiado pelo tempo em pausa
+func TestPauseAndResumeAuctionWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	repo := NewAuctionRepository(database)
+	defer repo.Scheduler.Stop()
+
+	auction, internalErr := auction_entity.CreateAuction(
+		"Test Product",
+		"Electronics",
+		"Test Description",
+		auction_entity.New,
+		auction_entity.WithEndsAt(time.Now().Add(2*time.Second)),
+	)
+	if internalErr != nil {
+		t.Fatalf("Erro ao criar leilão: %v", internalErr)
+	}
+
+	if internalErr := repo.CreateAuction(ctx, auction); internalErr != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", internalErr)
+	}
+
+	if internalErr := auction.Pause(time.Now()); internalErr != nil {
+		t.Fatalf("Erro ao pausar leilão: %v", internalErr)
+	}
+	if internalErr := repo.PauseAuction(ctx, auction); internalErr != nil {
+		t.Fatalf("Erro ao gravar pausa: %v", internalErr)
+	}
+
+	// Aguarda o término original passar
+	time.Sleep(3 * time.Second)
+
+	pausedAuction, internalErr := repo.FindAuctionById(ctx, auction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão: %v", internalErr)
+	}
+	if pausedAuction.Status != auction_entity.Paused {
+		t.Fatalf("Leilão pausado não deveria ser fechado, status: %v", pausedAuction.Status)
+	}
+
+	pausedAt := pausedAuction.PausedAt
+	if internalErr := pausedAuction.Resume(time.Now()); internalErr != nil {
+		t.Fatalf("Erro ao retomar leilão: %v", internalErr)
+	}
+	if internalErr := repo.ResumeAuction(ctx, pausedAuction, pausedAt); internalErr != nil {
+		t.Fatalf("Erro ao gravar retomada: %v", internalErr)
+	}
+
+	resumedAuction, internalErr := repo.FindAuctionById(ctx, auction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão: %v", internalErr)
+	}
+	if resumedAuction.Status != auction_entity.Active {
+		t.Errorf("Leilão retomado deveria estar ativo, status: %v", resumedAuction.Status)
+	}
+	if !resumedAuction.EndsAt.After(time.Now()) {
+		t.Errorf("Término deveria ser adiado pelo tempo em pausa, término: %v", resumedAuction.EndsAt)
+	}
+
+	// A segunda retomada encontra o leilão já ativo
+	if internalErr := repo.ResumeAuction(ctx, pausedAuction, pausedAt); internalErr == nil {
+		t.Error("Leilão já retomado não deveria ser retomado de novo")
+	}
+}
+
+// Teste da edição versionada: a versão anterior vira revisão e uma edição
+// baseada em versão desatualizada é recusada
+func TestUpdateAuctionWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	repo := NewAuctionRepository(database)
+	defer repo.Scheduler.Stop()
+
+	auction, internalErr := auction_entity.CreateAuction(
+		"Test Product",
+		"Electronics",
+		"Test Description",
+		auction_entity.New,
+		auction_entity.WithEndsAt(time.Now().Add(time.Hour)),
+	)
+	if internalErr != nil {
+		t.Fatalf("Erro ao criar leilão: %v", internalErr)
+	}
+
+	if internalErr := repo.CreateAuction(ctx, auction); internalErr != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", internalErr)
+	}
+
+	previous := *auction
+	category := "Computers"
+	if internalErr := auction.Edit(auction_entity.AuctionEdit{Category: &category}, false, time.Now()); internalErr != nil {
+		t.Fatalf("Erro ao editar leilão: %v", internalErr)
+	}
+	if internalErr := repo.UpdateAuction(ctx, auction, &previous); internalErr != nil {
+		t.Fatalf("Erro ao gravar edição: %v", internalErr)
+	}
+
+	// Segunda edição a partir da mesma versão
+	if internalErr := repo.UpdateAuction(ctx, auction, &previous); internalErr == nil {
+		t.Error("Edição de versão desatualizada deveria ser recusada")
+	}
+
+	foundAuction, internalErr := repo.FindAuctionById(ctx, auction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão: %v", internalErr)
+	}
+	if foundAuction.Category != category || foundAuction.Version != 2 {
+		t.Errorf("Edição não persistida: categoria %s, versão %d", foundAuction.Category, foundAuction.Version)
+	}
+
+	revisions, internalErr := repo.FindAuctionRevisions(ctx, auction.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar revisões: %v", internalErr)
+	}
+	if len(revisions) != 1 || revisions[0].Version != 1 || revisions[0].Auction.Category != "Electronics" {
+		t.Errorf("Revisão esperada da versão 1, obtido: %+v", revisions)
+	}
+}
+
+// Teste da publicação: o rascunho é gravado sem horários e só a primeira
+// publicação é aceita
+func TestPublishAuctionWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	repo := NewAuctionRepository(database)
+	defer repo.Scheduler.Stop()
+
+	draft := auction_entity.CreateDraftAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithDuration(time.Hour))
+	if internalErr := repo.CreateAuction(ctx, draft); internalErr != nil {
+		t.Fatalf("Erro ao salvar rascunho: %v", internalErr)
+	}
+
+	foundDraft, internalErr := repo.FindAuctionById(ctx, draft.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar rascunho: %v", internalErr)
+	}
+	if foundDraft.Status != auction_entity.Draft || !foundDraft.StartsAt.IsZero() || foundDraft.DraftDuration != time.Hour {
+		t.Fatalf("Rascunho não persistido corretamente: %+v", foundDraft)
+	}
+
+	if internalErr := foundDraft.Publish(time.Now()); internalErr != nil {
+		t.Fatalf("Erro ao publicar rascunho: %v", internalErr)
+	}
+	if internalErr := repo.PublishAuction(ctx, foundDraft); internalErr != nil {
+		t.Fatalf("Erro ao gravar publicação: %v", internalErr)
+	}
+
+	// A segunda publicação encontra o leilão já ativo
+	if internalErr := repo.PublishAuction(ctx, foundDraft); internalErr == nil {
+		t.Error("Leilão já publicado não deveria ser publicado de novo")
+	}
+
+	publishedAuction, internalErr := repo.FindAuctionById(ctx, draft.Id)
+	if internalErr != nil {
+		t.Fatalf("Erro ao buscar leilão publicado: %v", internalErr)
+	}
+	if publishedAuction.Status != auction_entity.Active ||
+		publishedAuction.EndsAt.Sub(publishedAuction.StartsAt) != time.Hour {
+		t.Errorf("Leilão publicado deveria estar ativo por 1h: %+v", publishedAuction)
+	}
+}
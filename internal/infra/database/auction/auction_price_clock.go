@@ -0,0 +1,166 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// AuctionPriceClock publica as reduções de preço dos leilões holandeses. Cada
+// leilão tem um timer para a próxima redução; o novo preço é calculado pelo
+// horário (PriceAt), então atrasos do timer ou reinícios não acumulam erro, e
+// o update só diminui o preço. O relógio para quando o preço chega ao piso ou
+// o leilão deixa de estar aberto.
+type AuctionPriceClock struct {
+	collection     *mongo.Collection
+	contextTimeout time.Duration
+	timers         map[string]*time.Timer
+	timersMutex    *sync.Mutex
+	stopped        bool
+}
+
+func NewAuctionPriceClock(collection *mongo.Collection) *AuctionPriceClock {
+	return &AuctionPriceClock{
+		collection:     collection,
+		contextTimeout: getAuctionContextTimeout(),
+		timers:         make(map[string]*time.Timer),
+		timersMutex:    &sync.Mutex{},
+	}
+}
+
+// Start retoma o relógio dos leilões holandeses agendados e ativos,
+// publicando de imediato as reduções que ocorreram com o serviço parado
+func (pc *AuctionPriceClock) Start(ctx context.Context) *internal_error.InternalError {
+	filter := bson.M{
+		"auction_type": auction_entity.Dutch,
+		"status": bson.M{"$in": []auction_entity.AuctionStatus{
+			auction_entity.Active, auction_entity.Scheduled}},
+	}
+
+	cursor, err := pc.collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find dutch auctions to resume", err)
+		return internal_error.NewInternalServerError("Error trying to find dutch auctions to resume")
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error trying to decode dutch auctions to resume", err)
+		return internal_error.NewInternalServerError("Error trying to decode dutch auctions to resume")
+	}
+
+	for _, auctionMongo := range auctionsMongo {
+		auctionEntity := auctionMongo.toEntity()
+		if auctionEntity.CurrentPrice > auctionEntity.PriceAt(time.Now()) {
+			pc.dropPrice(auctionEntity.Id)
+			continue
+		}
+
+		pc.Track(auctionEntity)
+	}
+
+	logger.Info("Auction price clock resumed dutch auctions",
+		zap.Int("auctions", len(auctionsMongo)))
+
+	return nil
+}
+
+// Stop cancela os timers pendentes; os preços continuam no banco e o
+// relógio é retomado no próximo Start
+func (pc *AuctionPriceClock) Stop() {
+	pc.timersMutex.Lock()
+	defer pc.timersMutex.Unlock()
+
+	pc.stopped = true
+	for auctionId, timer := range pc.timers {
+		timer.Stop()
+		delete(pc.timers, auctionId)
+	}
+}
+
+// Track agenda a próxima redução de preço do leilão, se ele for holandês e
+// o preço ainda não estiver no piso
+func (pc *AuctionPriceClock) Track(auctionEntity *auction_entity.Auction) {
+	nextDrop, ok := auctionEntity.NextPriceDrop(time.Now())
+	if !ok {
+		pc.Untrack(auctionEntity.Id)
+		return
+	}
+
+	pc.timersMutex.Lock()
+	defer pc.timersMutex.Unlock()
+
+	if pc.stopped {
+		return
+	}
+
+	if timer, ok := pc.timers[auctionEntity.Id]; ok {
+		timer.Stop()
+	}
+
+	auctionId := auctionEntity.Id
+	pc.timers[auctionId] = time.AfterFunc(time.Until(nextDrop), func() {
+		pc.dropPrice(auctionId)
+	})
+}
+
+func (pc *AuctionPriceClock) Untrack(auctionId string) {
+	pc.timersMutex.Lock()
+	defer pc.timersMutex.Unlock()
+
+	if timer, ok := pc.timers[auctionId]; ok {
+		timer.Stop()
+		delete(pc.timers, auctionId)
+	}
+}
+
+// dropPrice publica o preço atual do leilão e agenda a próxima redução. O
+// filtro garante que um leilão encerrado não tenha o preço alterado.
+func (pc *AuctionPriceClock) dropPrice(auctionId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), pc.contextTimeout)
+	defer cancel()
+
+	var auctionMongo AuctionEntityMongo
+	if err := pc.collection.FindOne(ctx, bson.M{"_id": auctionId}).Decode(&auctionMongo); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Error(fmt.Sprintf("Error trying to find dutch auction %s", auctionId), err)
+		}
+		pc.Untrack(auctionId)
+		return
+	}
+
+	auctionEntity := auctionMongo.toEntity()
+	if auctionEntity.Status != auction_entity.Active && auctionEntity.Status != auction_entity.Scheduled {
+		pc.Untrack(auctionId)
+		return
+	}
+
+	price := auctionEntity.PriceAt(time.Now())
+	filter := bson.M{
+		"_id":           auctionId,
+		"status":        bson.M{"$in": []auction_entity.AuctionStatus{auction_entity.Active, auction_entity.Scheduled}},
+		"current_price": bson.M{"$gt": price},
+	}
+	update := bson.M{"$set": bson.M{"current_price": price}}
+
+	result, err := pc.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to drop price of auction %s", auctionId), err)
+	} else if result.ModifiedCount > 0 {
+		logger.Info("Auction price dropped",
+			zap.String("auction_id", auctionId),
+			zap.Float64("current_price", price))
+	}
+
+	pc.Track(auctionEntity)
+}
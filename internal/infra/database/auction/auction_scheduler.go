@@ -0,0 +1,361 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"os"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// AuctionChangedHandler é chamado depois que um leilão muda (fechamento, pausa
+// ou edição), para que a apuração do resultado e os caches do leilão fiquem
+// fora da camada de infraestrutura
+type AuctionChangedHandler func(ctx context.Context, auctionId string) *internal_error.InternalError
+
+// AuctionScheduler mantém os timers de abertura e fechamento dos leilões. Os
+// horários ficam persistidos no MongoDB (starts_at e ends_at), então os timers
+// podem ser reconstruídos no startup e uma varredura periódica abre e fecha os
+// leilões vencidos. Cada leilão tem no máximo um timer pendente: a abertura
+// enquanto está agendado e o fechamento enquanto está ativo.
+type AuctionScheduler struct {
+	collection      *mongo.Collection
+	checkInterval   time.Duration
+	contextTimeout  time.Duration
+	timers          map[string]*time.Timer
+	timersMutex     *sync.Mutex
+	stopChannel     chan struct{}
+	stopOnce        *sync.Once
+	closingHandlers []AuctionChangedHandler
+	closedHandlers  []AuctionChangedHandler
+	pausedHandlers  []AuctionChangedHandler
+}
+
+func NewAuctionScheduler(collection *mongo.Collection) *AuctionScheduler {
+	return &AuctionScheduler{
+		collection:     collection,
+		checkInterval:  getAuctionCheckInterval(),
+		contextTimeout: getAuctionContextTimeout(),
+		timers:         make(map[string]*time.Timer),
+		timersMutex:    &sync.Mutex{},
+		stopChannel:    make(chan struct{}),
+		stopOnce:       &sync.Once{},
+	}
+}
+
+// Start reagenda os leilões agendados e ativos persistidos e inicia a
+// varredura periódica. Horários que já passaram disparam imediatamente.
+func (as *AuctionScheduler) Start(ctx context.Context) *internal_error.InternalError {
+	if err := as.recoverAuctions(ctx); err != nil {
+		return err
+	}
+
+	go as.sweepRoutine()
+
+	return nil
+}
+
+// Stop interrompe a varredura periódica e todos os timers pendentes. Os
+// horários continuam persistidos e são recuperados no próximo Start.
+func (as *AuctionScheduler) Stop() {
+	as.stopOnce.Do(func() {
+		close(as.stopChannel)
+
+		as.timersMutex.Lock()
+		defer as.timersMutex.Unlock()
+
+		for auctionId, timer := range as.timers {
+			timer.Stop()
+			delete(as.timers, auctionId)
+		}
+	})
+}
+
+// OnAuctionClosing registra um handler executado antes de o agendador fechar
+// um leilão vencido, para que os lances ainda na fila sejam processados com o
+// leilão ativo. Se o handler falhar o fechamento fica para a próxima
+// varredura. Deve ser chamado antes do Start.
+func (as *AuctionScheduler) OnAuctionClosing(handler AuctionChangedHandler) {
+	as.closingHandlers = append(as.closingHandlers, handler)
+}
+
+// OnAuctionClosed registra um handler para os leilões fechados pelo agendador.
+// Deve ser chamado antes do Start.
+func (as *AuctionScheduler) OnAuctionClosed(handler AuctionChangedHandler) {
+	as.closedHandlers = append(as.closedHandlers, handler)
+}
+
+// OnAuctionPaused registra um handler para os leilões pausados e retomados,
+// que mudam de status e de término sem passar pelo fechamento. Deve ser
+// chamado antes do Start.
+func (as *AuctionScheduler) OnAuctionPaused(handler AuctionChangedHandler) {
+	as.pausedHandlers = append(as.pausedHandlers, handler)
+}
+
+func (as *AuctionScheduler) Schedule(auctionId string, endsAt time.Time) {
+	as.setTimer(auctionId, endsAt, func() {
+		as.closeAuction(auctionId)
+	})
+}
+
+func (as *AuctionScheduler) ScheduleOpening(auctionId string, startsAt time.Time) {
+	as.setTimer(auctionId, startsAt, func() {
+		as.openAuction(auctionId)
+	})
+}
+
+func (as *AuctionScheduler) setTimer(auctionId string, at time.Time, action func()) {
+	as.timersMutex.Lock()
+	defer as.timersMutex.Unlock()
+
+	if timer, ok := as.timers[auctionId]; ok {
+		timer.Stop()
+	}
+
+	as.timers[auctionId] = time.AfterFunc(time.Until(at), action)
+}
+
+func (as *AuctionScheduler) Cancel(auctionId string) {
+	as.timersMutex.Lock()
+	defer as.timersMutex.Unlock()
+
+	if timer, ok := as.timers[auctionId]; ok {
+		timer.Stop()
+		delete(as.timers, auctionId)
+	}
+}
+
+func (as *AuctionScheduler) recoverAuctions(ctx context.Context) *internal_error.InternalError {
+	filter := bson.M{"status": bson.M{"$in": []auction_entity.AuctionStatus{
+		auction_entity.Active, auction_entity.Scheduled}}}
+
+	cursor, err := as.collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find pending auctions to schedule", err)
+		return internal_error.NewInternalServerError("Error trying to find pending auctions to schedule")
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error trying to decode pending auctions to schedule", err)
+		return internal_error.NewInternalServerError("Error trying to decode pending auctions to schedule")
+	}
+
+	for _, auctionMongo := range auctionsMongo {
+		if auctionMongo.Status == auction_entity.Scheduled {
+			as.ScheduleOpening(auctionMongo.Id, auctionMongo.toEntity().StartsAt)
+			continue
+		}
+
+		endsAt := auctionMongo.toEntity().EndsAt
+
+		// Leilões criados antes do ends_at ser persistido usam o intervalo padrão
+		if auctionMongo.EndsAt == 0 {
+			update := bson.M{"$set": bson.M{"ends_at": endsAt.Unix()}}
+			if _, err := as.collection.UpdateByID(ctx, auctionMongo.Id, update); err != nil {
+				logger.Error(fmt.Sprintf("Error trying to persist end time of auction %s", auctionMongo.Id), err)
+				return internal_error.NewInternalServerError("Error trying to persist auction end time")
+			}
+		}
+
+		as.Schedule(auctionMongo.Id, endsAt)
+	}
+
+	logger.Info("Auction scheduler recovered pending auctions",
+		zap.Int("auctions", len(auctionsMongo)))
+
+	return nil
+}
+
+func (as *AuctionScheduler) sweepRoutine() {
+	ticker := time.NewTicker(as.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-as.stopChannel:
+			return
+		case <-ticker.C:
+			as.openDueAuctions()
+			as.closeOverdueAuctions()
+		}
+	}
+}
+
+func (as *AuctionScheduler) openDueAuctions() {
+	ctx, cancel := context.WithTimeout(context.Background(), as.contextTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"status":    auction_entity.Scheduled,
+		"starts_at": bson.M{"$lte": time.Now().Unix()},
+	}
+
+	cursor, err := as.collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find due scheduled auctions", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error trying to decode due scheduled auctions", err)
+		return
+	}
+
+	for _, auctionMongo := range auctionsMongo {
+		as.openAuction(auctionMongo.Id)
+	}
+}
+
+func (as *AuctionScheduler) closeOverdueAuctions() {
+	ctx, cancel := context.WithTimeout(context.Background(), as.contextTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"status":  auction_entity.Active,
+		"ends_at": bson.M{"$gt": 0, "$lte": time.Now().Unix()},
+	}
+
+	cursor, err := as.collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find overdue auctions", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error trying to decode overdue auctions", err)
+		return
+	}
+
+	for _, auctionMongo := range auctionsMongo {
+		as.closeAuction(auctionMongo.Id)
+	}
+}
+
+// closeAuction fecha o leilão se ele continuar vencido depois dos handlers de
+// pré-fechamento, que podem estendê-lo pelo soft close
+func (as *AuctionScheduler) closeAuction(auctionId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), as.contextTimeout)
+	defer cancel()
+
+	for _, handler := range as.closingHandlers {
+		if err := handler(ctx, auctionId); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to prepare closing of auction %s", auctionId), err)
+			return
+		}
+	}
+
+	filter := bson.M{
+		"_id":     auctionId,
+		"status":  auction_entity.Active,
+		"ends_at": bson.M{"$lte": time.Now().Unix()},
+	}
+	update := bson.M{"$set": bson.M{"status": auction_entity.Completed}}
+
+	result, err := as.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to close auction %s", auctionId), err)
+		return
+	}
+
+	if result.ModifiedCount == 0 {
+		return
+	}
+
+	as.NotifyClosed(ctx, auctionId)
+}
+
+// NotifyClosed descarta o timer de um leilão já fechado e executa os handlers
+// de fechamento. É chamado pelo próprio agendador e pelos encerramentos que
+// acontecem fora dele, como a aceitação do preço em um leilão holandês.
+func (as *AuctionScheduler) NotifyClosed(ctx context.Context, auctionId string) {
+	as.Cancel(auctionId)
+
+	logger.Info("Auction closed", zap.String("auction_id", auctionId))
+
+	for _, handler := range as.closedHandlers {
+		if err := handler(ctx, auctionId); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to handle closing of auction %s", auctionId), err)
+		}
+	}
+}
+
+// NotifyPaused suspende o fechamento de um leilão pausado, ou o reagenda para
+// o novo término quando ele é retomado, e executa os handlers de pausa
+func (as *AuctionScheduler) NotifyPaused(ctx context.Context, auctionEntity *auction_entity.Auction) {
+	if auctionEntity.Status == auction_entity.Paused {
+		as.Cancel(auctionEntity.Id)
+	} else {
+		as.Schedule(auctionEntity.Id, auctionEntity.EndsAt)
+	}
+
+	logger.Info("Auction pause changed",
+		zap.String("auction_id", auctionEntity.Id),
+		zap.Int("status", int(auctionEntity.Status)))
+
+	for _, handler := range as.pausedHandlers {
+		if err := handler(ctx, auctionEntity.Id); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to handle pause of auction %s", auctionEntity.Id), err)
+		}
+	}
+}
+
+// openAuction ativa um leilão agendado e agenda o seu fechamento
+func (as *AuctionScheduler) openAuction(auctionId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), as.contextTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":       auctionId,
+		"status":    auction_entity.Scheduled,
+		"starts_at": bson.M{"$lte": time.Now().Unix()},
+	}
+	update := bson.M{"$set": bson.M{"status": auction_entity.Active}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var auctionMongo AuctionEntityMongo
+	if err := as.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionMongo); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Error(fmt.Sprintf("Error trying to open auction %s", auctionId), err)
+		}
+		return
+	}
+
+	logger.Info("Auction opened", zap.String("auction_id", auctionId))
+
+	as.Schedule(auctionId, auctionMongo.toEntity().EndsAt)
+}
+
+func getAuctionCheckInterval() time.Duration {
+	checkInterval := os.Getenv("AUCTION_CHECK_INTERVAL")
+	duration, err := time.ParseDuration(checkInterval)
+	if err != nil || duration <= 0 {
+		return 10 * time.Second
+	}
+
+	return duration
+}
+
+func getAuctionContextTimeout() time.Duration {
+	contextTimeout := os.Getenv("AUCTION_CONTEXT_TIMEOUT")
+	duration, err := time.ParseDuration(contextTimeout)
+	if err != nil || duration <= 0 {
+		return 30 * time.Second
+	}
+
+	return duration
+}
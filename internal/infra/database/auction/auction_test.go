@@ -475,3 +475,30 @@ func TestAuctionIntervalValidation(t *testing.T) {
 		})
 	}
 }
+
+// Teste de configuração do intervalo de varredura do agendador
+func TestAuctionCheckIntervalConfiguration(t *testing.T) {
+	testCases := []struct {
+		envValue    string
+		expected    time.Duration
+		description string
+	}{
+		{"5s", 5 * time.Second, "5 segundos"},
+		{"1m", 1 * time.Minute, "1 minuto"},
+		{"", 10 * time.Second, "vazio (deve usar padrão)"},
+		{"invalid", 10 * time.Second, "valor inválido (deve usar padrão)"},
+		{"-1s", 10 * time.Second, "valor negativo (deve usar padrão)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			os.Setenv("AUCTION_CHECK_INTERVAL", tc.envValue)
+			defer os.Unsetenv("AUCTION_CHECK_INTERVAL")
+
+			interval := getAuctionCheckInterval()
+			if interval != tc.expected {
+				t.Errorf("Para %s: esperado %v, obtido %v", tc.envValue, tc.expected, interval)
+			}
+		})
+	}
+}
@@ -8,63 +8,262 @@ import (
 	"os"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AuctionEntityMongo struct {
-	Id          string                          `bson:"_id"`
-	ProductName string                          `bson:"product_name"`
-	Category    string                          `bson:"category"`
-	Description string                          `bson:"description"`
-	Condition   auction_entity.ProductCondition `bson:"condition"`
-	Status      auction_entity.AuctionStatus    `bson:"status"`
-	Timestamp   int64                           `bson:"timestamp"`
+	Id                   string                          `bson:"_id"`
+	ProductName          string                          `bson:"product_name"`
+	Category             string                          `bson:"category"`
+	Description          string                          `bson:"description"`
+	Condition            auction_entity.ProductCondition `bson:"condition"`
+	Type                 auction_entity.AuctionType      `bson:"auction_type"`
+	Status               auction_entity.AuctionStatus    `bson:"status"`
+	Timestamp            int64                           `bson:"timestamp"`
+	StartsAt             int64                           `bson:"starts_at"`
+	EndsAt               int64                           `bson:"ends_at"`
+	ReservePrice         float64                         `bson:"reserve_price"`
+	StartingPrice        float64                         `bson:"starting_price"`
+	MinIncrement         float64                         `bson:"min_increment"`
+	IncrementTiers       []IncrementTierMongo            `bson:"increment_tiers"`
+	PriceDecrement       float64                         `bson:"price_decrement,omitempty"`
+	PriceDropInterval    int64                           `bson:"price_drop_interval,omitempty"`
+	CurrentPrice         float64                         `bson:"current_price,omitempty"`
+	Quantity             int                             `bson:"quantity"`
+	PricingRule          auction_entity.PricingRule      `bson:"pricing_rule"`
+	BuyNowPrice          float64                         `bson:"buy_now_price,omitempty"`
+	RelistedFromId       string                          `bson:"relisted_from_id,omitempty"`
+	PausedAt             int64                           `bson:"paused_at,omitempty"`
+	PausedDuration       int64                           `bson:"paused_duration,omitempty"`
+	MaxAutoRelists       int                             `bson:"max_auto_relists,omitempty"`
+	RelistPriceReduction float64                         `bson:"relist_price_reduction,omitempty"`
+	RelistCount          int                             `bson:"relist_count,omitempty"`
+	OriginalAuctionId    string                          `bson:"original_auction_id,omitempty"`
+	Version              int                             `bson:"version"`
+	Clarifications       []ClarificationMongo            `bson:"clarifications,omitempty"`
+	DraftDuration        int64                           `bson:"draft_duration,omitempty"`
+}
+
+type IncrementTierMongo struct {
+	From      float64 `bson:"from"`
+	Increment float64 `bson:"increment"`
+}
+
+type ClarificationMongo struct {
+	Text      string `bson:"text"`
+	CreatedAt int64  `bson:"created_at"`
 }
 type AuctionRepository struct {
-	Collection      *mongo.Collection
+	Collection         *mongo.Collection
+	RevisionCollection *mongo.Collection
+	Scheduler          *AuctionScheduler
+	PriceClock         *AuctionPriceClock
+	updatedHandlers    []AuctionChangedHandler
 }
 
 func NewAuctionRepository(database *mongo.Database) *AuctionRepository {
+	collection := database.Collection("auctions")
+
 	return &AuctionRepository{
-		Collection:      database.Collection("auctions"),
+		Collection:         collection,
+		RevisionCollection: database.Collection("auction_revisions"),
+		Scheduler:          NewAuctionScheduler(collection),
+		PriceClock:         NewAuctionPriceClock(collection),
 	}
 }
 
 func (ar *AuctionRepository) CreateAuction(
 	ctx context.Context,
 	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
-	auctionEntityMongo := &AuctionEntityMongo{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   auctionEntity.Condition,
-		Status:      auctionEntity.Status,
-		Timestamp:   auctionEntity.Timestamp.Unix(),
+	// Rascunhos não têm horários padrão nem timers até a publicação
+	if auctionEntity.Status != auction_entity.Draft {
+		setDefaultSchedule(auctionEntity, auctionEntity.Timestamp)
 	}
+
+	auctionEntityMongo := newAuctionEntityMongo(auctionEntity)
 	_, err := ar.Collection.InsertOne(ctx, auctionEntityMongo)
 	if err != nil {
 		logger.Error("Error trying to insert auction", err)
 		return internal_error.NewInternalServerError("Error trying to insert auction")
 	}
 
-	go func() {
-		select {
-		case <-time.After(getAuctionInterval()):
-			update := bson.M{"$set": bson.M{"status": auction_entity.Completed}}
-			filter := bson.M{"_id": auctionEntity.Id}
+	if auctionEntity.Status != auction_entity.Draft {
+		ar.startAuctionClock(auctionEntity)
+	}
+
+	return nil
+}
+
+// setDefaultSchedule preenche o início e o término não informados
+func setDefaultSchedule(auctionEntity *auction_entity.Auction, startsAt time.Time) {
+	if auctionEntity.StartsAt.IsZero() {
+		auctionEntity.StartsAt = startsAt
+	}
+
+	if auctionEntity.EndsAt.IsZero() {
+		auctionEntity.EndsAt = auctionEntity.StartsAt.Add(getAuctionInterval())
+	}
+}
+
+// startAuctionClock agenda a abertura ou o encerramento do leilão e, nos
+// holandeses, as reduções de preço
+func (ar *AuctionRepository) startAuctionClock(auctionEntity *auction_entity.Auction) {
+	if auctionEntity.Status == auction_entity.Scheduled {
+		ar.Scheduler.ScheduleOpening(auctionEntity.Id, auctionEntity.StartsAt)
+	} else {
+		ar.Scheduler.Schedule(auctionEntity.Id, auctionEntity.EndsAt)
+	}
+
+	if auctionEntity.Type == auction_entity.Dutch {
+		ar.PriceClock.Track(auctionEntity)
+	}
+}
+
+func newAuctionEntityMongo(auctionEntity *auction_entity.Auction) *AuctionEntityMongo {
+	var incrementTiers []IncrementTierMongo
+	for _, tier := range auctionEntity.IncrementTiers {
+		incrementTiers = append(incrementTiers, IncrementTierMongo{
+			From:      tier.From,
+			Increment: tier.Increment,
+		})
+	}
+
+	var clarifications []ClarificationMongo
+	for _, clarification := range auctionEntity.Clarifications {
+		clarifications = append(clarifications, ClarificationMongo{
+			Text:      clarification.Text,
+			CreatedAt: clarification.CreatedAt.Unix(),
+		})
+	}
 
-			_, err := ar.Collection.UpdateOne(ctx, filter, update)
-			if err != nil {
-				logger.Error("Error trying to update auction status", err)
-				return
-			}
+	return &AuctionEntityMongo{
+		Id:                   auctionEntity.Id,
+		ProductName:          auctionEntity.ProductName,
+		Category:             auctionEntity.Category,
+		Description:          auctionEntity.Description,
+		Condition:            auctionEntity.Condition,
+		Type:                 auctionEntity.Type,
+		Status:               auctionEntity.Status,
+		Timestamp:            auctionEntity.Timestamp.Unix(),
+		StartsAt:             unixOrZero(auctionEntity.StartsAt),
+		EndsAt:               unixOrZero(auctionEntity.EndsAt),
+		ReservePrice:         auctionEntity.ReservePrice,
+		StartingPrice:        auctionEntity.StartingPrice,
+		MinIncrement:         auctionEntity.MinIncrement,
+		IncrementTiers:       incrementTiers,
+		PriceDecrement:       auctionEntity.PriceDecrement,
+		PriceDropInterval:    int64(auctionEntity.PriceDropInterval / time.Second),
+		CurrentPrice:         auctionEntity.CurrentPrice,
+		Quantity:             auctionEntity.Quantity,
+		PricingRule:          auctionEntity.PricingRule,
+		BuyNowPrice:          auctionEntity.BuyNowPrice,
+		RelistedFromId:       auctionEntity.RelistedFromId,
+		PausedDuration:       int64(auctionEntity.PausedDuration / time.Second),
+		MaxAutoRelists:       auctionEntity.MaxAutoRelists,
+		RelistPriceReduction: auctionEntity.RelistPriceReduction,
+		RelistCount:          auctionEntity.RelistCount,
+		OriginalAuctionId:    auctionEntity.OriginalAuctionId,
+		Version:              auctionEntity.Version,
+		Clarifications:       clarifications,
+		DraftDuration:        int64(auctionEntity.DraftDuration / time.Second),
+	}
+}
+
+// unixOrZero grava horários vazios (como os de rascunhos) como 0
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func (am *AuctionEntityMongo) toEntity() *auction_entity.Auction {
+	startsAt := time.Unix(am.StartsAt, 0)
+	if am.StartsAt == 0 {
+		startsAt = time.Unix(am.Timestamp, 0)
+	}
+
+	endsAt := time.Unix(am.EndsAt, 0)
+	if am.EndsAt == 0 {
+		endsAt = startsAt.Add(getAuctionInterval())
+	}
+
+	// Rascunhos mantêm vazios os horários não informados
+	if am.Status == auction_entity.Draft {
+		startsAt, endsAt = time.Time{}, time.Time{}
+		if am.StartsAt != 0 {
+			startsAt = time.Unix(am.StartsAt, 0)
+		}
+		if am.EndsAt != 0 {
+			endsAt = time.Unix(am.EndsAt, 0)
 		}
-	}()
+	}
 
-	return nil
+	var clarifications []auction_entity.Clarification
+	for _, clarification := range am.Clarifications {
+		clarifications = append(clarifications, auction_entity.Clarification{
+			Text:      clarification.Text,
+			CreatedAt: time.Unix(clarification.CreatedAt, 0),
+		})
+	}
+
+	// Leilões criados antes do versionamento estão na primeira versão
+	version := am.Version
+	if version == 0 {
+		version = 1
+	}
+
+	var pausedAt time.Time
+	if am.PausedAt != 0 {
+		pausedAt = time.Unix(am.PausedAt, 0)
+	}
 
+	// Leilões criados antes da quantidade ser persistida têm uma unidade
+	quantity := am.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	var incrementTiers []auction_entity.IncrementTier
+	for _, tier := range am.IncrementTiers {
+		incrementTiers = append(incrementTiers, auction_entity.IncrementTier{
+			From:      tier.From,
+			Increment: tier.Increment,
+		})
+	}
+
+	return &auction_entity.Auction{
+		Id:                   am.Id,
+		ProductName:          am.ProductName,
+		Category:             am.Category,
+		Description:          am.Description,
+		Condition:            am.Condition,
+		Type:                 am.Type,
+		Status:               am.Status,
+		Timestamp:            time.Unix(am.Timestamp, 0),
+		StartsAt:             startsAt,
+		EndsAt:               endsAt,
+		ReservePrice:         am.ReservePrice,
+		StartingPrice:        am.StartingPrice,
+		MinIncrement:         am.MinIncrement,
+		IncrementTiers:       incrementTiers,
+		PriceDecrement:       am.PriceDecrement,
+		PriceDropInterval:    time.Duration(am.PriceDropInterval) * time.Second,
+		CurrentPrice:         am.CurrentPrice,
+		Quantity:             quantity,
+		PricingRule:          am.PricingRule,
+		BuyNowPrice:          am.BuyNowPrice,
+		RelistedFromId:       am.RelistedFromId,
+		PausedAt:             pausedAt,
+		PausedDuration:       time.Duration(am.PausedDuration) * time.Second,
+		MaxAutoRelists:       am.MaxAutoRelists,
+		RelistPriceReduction: am.RelistPriceReduction,
+		RelistCount:          am.RelistCount,
+		OriginalAuctionId:    am.OriginalAuctionId,
+		Version:              version,
+		Clarifications:       clarifications,
+		DraftDuration:        time.Duration(am.DraftDuration) * time.Second,
+	}
 }
 
 // getAuctionInterval obtém o intervalo de tempo do leilão das variáveis de ambiente
@@ -0,0 +1,145 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// AuctionRevisionMongo guarda uma versão anterior do leilão. O _id combina o
+// leilão e a versão, então duas edições concorrentes da mesma versão não
+// gravam duas revisões.
+type AuctionRevisionMongo struct {
+	Id         string             `bson:"_id"`
+	AuctionId  string             `bson:"auction_id"`
+	Version    int                `bson:"version"`
+	Auction    AuctionEntityMongo `bson:"auction"`
+	ReplacedAt int64              `bson:"replaced_at"`
+}
+
+// OnAuctionUpdated registra um handler para os leilões editados, para que
+// caches do leilão sejam descartados. Deve ser chamado antes do Start.
+func (ar *AuctionRepository) OnAuctionUpdated(handler AuctionChangedHandler) {
+	ar.updatedHandlers = append(ar.updatedHandlers, handler)
+}
+
+// UpdateAuction grava a edição do leilão. A versão anterior é guardada como
+// revisão antes do update, que só é aplicado se o leilão ainda estiver na
+// versão lida e aberto; caso contrário a revisão é descartada.
+func (ar *AuctionRepository) UpdateAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction,
+	previous *auction_entity.Auction) *internal_error.InternalError {
+	revision := AuctionRevisionMongo{
+		Id:         fmt.Sprintf("%s:%d", previous.Id, previous.Version),
+		AuctionId:  previous.Id,
+		Version:    previous.Version,
+		Auction:    *newAuctionEntityMongo(previous),
+		ReplacedAt: time.Now().Unix(),
+	}
+
+	if _, err := ar.RevisionCollection.InsertOne(ctx, revision); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewBadRequestError("Auction was edited concurrently, please try again")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to save revision of auction %s", previous.Id), err)
+		return internal_error.NewInternalServerError("Error trying to save auction revision")
+	}
+
+	auctionEntityMongo := newAuctionEntityMongo(auctionEntity)
+	filter := bson.M{
+		"_id":     auctionEntity.Id,
+		"version": previous.Version,
+		"status": bson.M{"$nin": []auction_entity.AuctionStatus{
+			auction_entity.Completed, auction_entity.EndedWithoutSale, auction_entity.Cancelled}},
+	}
+	if previous.Version == 1 {
+		// Leilões criados antes do versionamento não têm o campo
+		filter["version"] = bson.M{"$in": bson.A{1, nil}}
+	}
+	update := bson.M{"$set": bson.M{
+		"description":     auctionEntityMongo.Description,
+		"category":        auctionEntityMongo.Category,
+		"starting_price":  auctionEntityMongo.StartingPrice,
+		"reserve_price":   auctionEntityMongo.ReservePrice,
+		"min_increment":   auctionEntityMongo.MinIncrement,
+		"increment_tiers": auctionEntityMongo.IncrementTiers,
+		"pricing_rule":    auctionEntityMongo.PricingRule,
+		"buy_now_price":   auctionEntityMongo.BuyNowPrice,
+		"current_price":   auctionEntityMongo.CurrentPrice,
+		"clarifications":  auctionEntityMongo.Clarifications,
+		"version":         auctionEntityMongo.Version,
+	}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err == nil && result.MatchedCount == 0 {
+		ar.discardRevision(ctx, revision.Id)
+		return internal_error.NewBadRequestError("Auction was edited or closed concurrently, please try again")
+	}
+	if err != nil {
+		ar.discardRevision(ctx, revision.Id)
+		logger.Error(fmt.Sprintf("Error trying to update auction %s", auctionEntity.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update auction")
+	}
+
+	logger.Info("Auction updated",
+		zap.String("auction_id", auctionEntity.Id),
+		zap.Int("version", auctionEntity.Version))
+
+	if auctionEntity.Type == auction_entity.Dutch && auctionEntity.Status != auction_entity.Draft {
+		ar.PriceClock.Track(auctionEntity)
+	}
+
+	for _, handler := range ar.updatedHandlers {
+		if err := handler(ctx, auctionEntity.Id); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to handle update of auction %s", auctionEntity.Id), err)
+		}
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) FindAuctionRevisions(
+	ctx context.Context, auctionId string) ([]auction_entity.AuctionRevision, *internal_error.InternalError) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+
+	cursor, err := ar.RevisionCollection.Find(ctx, bson.M{"auction_id": auctionId}, opts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find revisions of auction %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction revisions")
+	}
+	defer cursor.Close(ctx)
+
+	var revisionsMongo []AuctionRevisionMongo
+	if err := cursor.All(ctx, &revisionsMongo); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to decode revisions of auction %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction revisions")
+	}
+
+	revisions := make([]auction_entity.AuctionRevision, 0, len(revisionsMongo))
+	for _, revisionMongo := range revisionsMongo {
+		revisions = append(revisions, auction_entity.AuctionRevision{
+			AuctionId:  revisionMongo.AuctionId,
+			Version:    revisionMongo.Version,
+			Auction:    *revisionMongo.Auction.toEntity(),
+			ReplacedAt: time.Unix(revisionMongo.ReplacedAt, 0),
+		})
+	}
+
+	return revisions, nil
+}
+
+func (ar *AuctionRepository) discardRevision(ctx context.Context, revisionId string) {
+	if _, err := ar.RevisionCollection.DeleteOne(ctx, bson.M{"_id": revisionId}); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to discard auction revision %s", revisionId), err)
+	}
+}
@@ -0,0 +1,48 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// PublishAuction grava o rascunho publicado e só então agenda a abertura ou o
+// encerramento, como na criação. A gravação só é feita se o leilão ainda for
+// um rascunho, para que duas publicações concorrentes não agendem o leilão
+// duas vezes.
+func (ar *AuctionRepository) PublishAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	setDefaultSchedule(auctionEntity, auctionEntity.StartsAt)
+
+	filter := bson.M{"_id": auctionEntity.Id, "status": auction_entity.Draft}
+
+	result, err := ar.Collection.ReplaceOne(ctx, filter, newAuctionEntityMongo(auctionEntity))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to publish auction %s", auctionEntity.Id), err)
+		return internal_error.NewInternalServerError("Error trying to publish auction")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewBadRequestError("Auction status has changed, please try again")
+	}
+
+	logger.Info("Auction published",
+		zap.String("auction_id", auctionEntity.Id),
+		zap.Int("status", int(auctionEntity.Status)))
+
+	ar.startAuctionClock(auctionEntity)
+
+	for _, handler := range ar.updatedHandlers {
+		if err := handler(ctx, auctionEntity.Id); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to handle publication of auction %s", auctionEntity.Id), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+func (ar *AuctionRepository) UpdateAuctionStatus(
+	ctx context.Context,
+	auctionId string,
+	status auction_entity.AuctionStatus) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId}
+	update := bson.M{"$set": bson.M{"status": status}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update status of auction %s", auctionId), err)
+		return internal_error.NewInternalServerError("Error trying to update auction status")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Auction not found with this id = %s", auctionId))
+	}
+
+	return nil
+}
+
+// ChangeAuctionStatus muda o status do leilão apenas se ele ainda estiver em
+// from, para que mudanças concorrentes (como o fechamento pelo agendador) não
+// sejam sobrescritas. Quando o leilão deixa de receber lances os timers são
+// cancelados e os handlers de fechamento executados.
+func (ar *AuctionRepository) ChangeAuctionStatus(
+	ctx context.Context,
+	auctionId string,
+	from, to auction_entity.AuctionStatus) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId, "status": from}
+	update := bson.M{"$set": bson.M{"status": to}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to change status of auction %s", auctionId), err)
+		return internal_error.NewInternalServerError("Error trying to change auction status")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewBadRequestError("Auction status has changed, please try again")
+	}
+
+	logger.Info("Auction status changed",
+		zap.String("auction_id", auctionId),
+		zap.Int("from", int(from)),
+		zap.Int("to", int(to)))
+
+	if to == auction_entity.Completed || to == auction_entity.Cancelled {
+		ar.NotifyAuctionClosed(ctx, auctionId)
+	}
+
+	return nil
+}
+
+// PauseAuction grava a pausa de um leilão ativo que ainda não terminou. O
+// agendador e a varredura só fecham leilões ativos, então o leilão pausado
+// não é fechado enquanto não for retomado.
+func (ar *AuctionRepository) PauseAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	filter := bson.M{
+		"_id":     auctionEntity.Id,
+		"status":  auction_entity.Active,
+		"ends_at": bson.M{"$gt": auctionEntity.PausedAt.Unix()},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":    auction_entity.Paused,
+		"paused_at": auctionEntity.PausedAt.Unix(),
+	}}
+
+	if err := ar.updatePause(ctx, auctionEntity, filter, update); err != nil {
+		return err
+	}
+
+	ar.PriceClock.Untrack(auctionEntity.Id)
+
+	return nil
+}
+
+// ResumeAuction grava a retomada de um leilão pausado em pausedAt, com o
+// término e o tempo em pausa já calculados pela entidade
+func (ar *AuctionRepository) ResumeAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction,
+	pausedAt time.Time) *internal_error.InternalError {
+	filter := bson.M{
+		"_id":       auctionEntity.Id,
+		"status":    auction_entity.Paused,
+		"paused_at": pausedAt.Unix(),
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":          auction_entity.Active,
+			"ends_at":         auctionEntity.EndsAt.Unix(),
+			"paused_duration": int64(auctionEntity.PausedDuration / time.Second),
+		},
+		"$unset": bson.M{"paused_at": ""},
+	}
+
+	if err := ar.updatePause(ctx, auctionEntity, filter, update); err != nil {
+		return err
+	}
+
+	if auctionEntity.Type == auction_entity.Dutch {
+		ar.PriceClock.Track(auctionEntity)
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) updatePause(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction,
+	filter, update bson.M) *internal_error.InternalError {
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to change pause of auction %s", auctionEntity.Id), err)
+		return internal_error.NewInternalServerError("Error trying to change auction status")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewBadRequestError("Auction status has changed, please try again")
+	}
+
+	ar.Scheduler.NotifyPaused(ctx, auctionEntity)
+
+	return nil
+}
+
+// ExtendAuctionEndTime adia o término de um leilão ativo em extension quando,
+// no instante at do lance, ele terminava dentro da janela informada (soft
+// close). A condição é avaliada no próprio update, então lances concorrentes
+// não estendem um leilão que já saiu da janela. Retorna nil quando o leilão
+// não foi estendido.
+func (ar *AuctionRepository) ExtendAuctionEndTime(
+	ctx context.Context,
+	auctionId string,
+	at time.Time,
+	window, extension time.Duration) (*time.Time, *internal_error.InternalError) {
+	filter := bson.M{
+		"_id":    auctionId,
+		"status": auction_entity.Active,
+		"ends_at": bson.M{
+			"$gte": at.Unix(),
+			"$lte": at.Add(window).Unix(),
+		},
+	}
+	update := bson.M{"$inc": bson.M{"ends_at": int64(extension / time.Second)}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var auctionEntityMongo AuctionEntityMongo
+	if err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to extend auction %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to extend auction end time")
+	}
+
+	endsAt := time.Unix(auctionEntityMongo.EndsAt, 0)
+	ar.Scheduler.Schedule(auctionId, endsAt)
+
+	logger.Info("Auction end time extended",
+		zap.String("auction_id", auctionId),
+		zap.Time("ends_at", endsAt))
+
+	return &endsAt, nil
+}
+
+// CompleteAuction encerra um leilão ativo antes do término, no instante at, e
+// retorna o leilão como ficou no encerramento, para que o chamador use o preço
+// vigente naquele momento. No leilão holandês o current_price passa, no mesmo
+// update, ao preço de at (PriceAt) quando o relógio de preços ainda não
+// aplicou a última queda. Retorna nil quando o leilão já não estava ativo. Os
+// timers e os handlers de fechamento ficam a cargo do chamador, via
+// NotifyAuctionClosed, depois que o resultado estiver gravado.
+func (ar *AuctionRepository) CompleteAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction,
+	at time.Time) (*auction_entity.Auction, *internal_error.InternalError) {
+	auctionId := auctionEntity.Id
+	filter := bson.M{
+		"_id":     auctionId,
+		"status":  auction_entity.Active,
+		"ends_at": bson.M{"$gt": at.Unix()},
+	}
+	update := bson.M{"$set": bson.M{"status": auction_entity.Completed}}
+	if auctionEntity.Type == auction_entity.Dutch {
+		update["$min"] = bson.M{"current_price": auctionEntity.PriceAt(at)}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var auctionEntityMongo AuctionEntityMongo
+	if err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to complete auction %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to complete auction")
+	}
+
+	return auctionEntityMongo.toEntity(), nil
+}
+
+// NotifyAuctionClosed para o relógio de preços e o timer de fechamento de
+// um leilão encerrado fora do agendador e executa os handlers de fechamento
+func (ar *AuctionRepository) NotifyAuctionClosed(ctx context.Context, auctionId string) {
+	ar.PriceClock.Untrack(auctionId)
+	ar.Scheduler.NotifyClosed(ctx, auctionId)
+}
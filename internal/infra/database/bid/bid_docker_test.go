@@ -0,0 +1,438 @@
+//go:build integration
+// +build integration
+
+package bid
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Teste da inserção em lote: um único BulkWrite não ordenado, com a falha de
+// um lance (id duplicado) voltando apenas no resultado dele
+func TestCreateBidBulkInsertWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New)
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	newBid := func(amount float64) bid_entity.Bid {
+		bid, _ := bid_entity.CreateBid(uuid.New().String(), auctionEntity.Id, amount)
+		return *bid
+	}
+
+	first, tooLow, second := newBid(100), newBid(90), newBid(110)
+	duplicated := newBid(120)
+	duplicated.Id = first.Id
+
+	results, ierr := bidRepository.CreateBid(ctx, []bid_entity.Bid{first, tooLow, second, duplicated})
+	if ierr != nil {
+		t.Fatalf("Erro ao inserir lote: %v", ierr)
+	}
+
+	expected := []bid_entity.BidResult{
+		{BidId: first.Id, Status: bid_entity.Accepted},
+		{BidId: tooLow.Id, Status: bid_entity.Rejected, Reason: bid_entity.BidTooLow},
+		{BidId: second.Id, Status: bid_entity.Accepted},
+		{BidId: duplicated.Id, Status: bid_entity.Rejected, Reason: bid_entity.ProcessingFailed},
+	}
+	for i, result := range results {
+		if result.BidId != expected[i].BidId || result.Status != expected[i].Status || result.Reason != expected[i].Reason {
+			t.Errorf("Resultado %d esperado: %+v, obtido: %+v", i, expected[i], result)
+		}
+	}
+
+	winningBid, ierr := bidRepository.FindWinningBidByAuctionId(ctx, auctionEntity.Id)
+	if ierr != nil || winningBid.Id != second.Id {
+		t.Errorf("Maior lance deveria ser %s, obtido: %+v (erro: %v)", second.Id, winningBid, ierr)
+	}
+
+	if ierr := bidRepository.ValidateBid(ctx, newBid(115)); ierr != nil {
+		t.Errorf("Maior lance em memória deveria voltar a 110 após a falha, erro: %v", ierr)
+	}
+}
+
+// Teste do lance enfileirado: gravado como Queued, não conta para o vencedor
+// e o lote grava o resultado no mesmo documento
+func TestQueueBidWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New)
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	queuedBid, _ := bid_entity.CreateBid(uuid.New().String(), auctionEntity.Id, 100)
+	if ierr := bidRepository.QueueBid(ctx, *queuedBid); ierr != nil {
+		t.Fatalf("Erro ao enfileirar lance: %v", ierr)
+	}
+
+	foundBid, ierr := bidRepository.FindBidById(ctx, queuedBid.Id)
+	if ierr != nil || foundBid.Status != bid_entity.Queued {
+		t.Fatalf("Lance deveria estar gravado como enfileirado: %+v (erro: %v)", foundBid, ierr)
+	}
+	if _, ierr := bidRepository.FindWinningBidByAuctionId(ctx, auctionEntity.Id); ierr == nil || ierr.Err != "not_found" {
+		t.Errorf("Lance enfileirado não deveria contar para o vencedor, erro: %v", ierr)
+	}
+
+	if _, ierr := bidRepository.CreateBid(ctx, []bid_entity.Bid{*queuedBid}); ierr != nil {
+		t.Fatalf("Erro ao processar lote: %v", ierr)
+	}
+
+	foundBid, ierr = bidRepository.FindBidById(ctx, queuedBid.Id)
+	if ierr != nil || foundBid.Status != bid_entity.Accepted {
+		t.Errorf("Lote deveria gravar o resultado no lance enfileirado: %+v (erro: %v)", foundBid, ierr)
+	}
+
+	// Um lance com resultado gravado não é descartado nem reprocessado
+	if ierr := bidRepository.DiscardQueuedBid(ctx, queuedBid.Id); ierr != nil {
+		t.Fatalf("Erro ao descartar lance: %v", ierr)
+	}
+	results, ierr := bidRepository.CreateBid(ctx, []bid_entity.Bid{*queuedBid})
+	if ierr != nil || results[0].Status != bid_entity.Rejected {
+		t.Errorf("Lance já processado não deveria ser gravado de novo: %+v (erro: %v)", results, ierr)
+	}
+	if foundBid, _ = bidRepository.FindBidById(ctx, queuedBid.Id); foundBid == nil || foundBid.Status != bid_entity.Accepted {
+		t.Errorf("Resultado gravado não deveria mudar: %+v", foundBid)
+	}
+}
+
+// Teste da trava de edição: conta os lances na fila e segura novos lances do
+// leilão até o unlock
+func TestLockAuctionBidsWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New)
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	hasBids, unlock, ierr := bidRepository.LockAuctionBids(ctx, auctionEntity.Id)
+	if ierr != nil || hasBids {
+		t.Fatalf("Leilão sem lances não deveria ter lances: %v (erro: %v)", hasBids, ierr)
+	}
+
+	queuedBid, _ := bid_entity.CreateBid(uuid.New().String(), auctionEntity.Id, 100)
+	queued := make(chan struct{})
+	go func() {
+		bidRepository.QueueBid(ctx, *queuedBid)
+		close(queued)
+	}()
+
+	select {
+	case <-queued:
+		t.Fatal("Lance não deveria ser enfileirado com o leilão travado")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	<-queued
+
+	hasBids, unlock, ierr = bidRepository.LockAuctionBids(ctx, auctionEntity.Id)
+	if ierr != nil || !hasBids {
+		t.Errorf("Lance na fila deveria contar como lance: %v (erro: %v)", hasBids, ierr)
+	}
+	unlock()
+}
+
+// Teste do leilão holandês: o primeiro a aceitar o preço vence e encerra o
+// leilão; aceitações seguintes e lances comuns são recusados
+func TestAcceptAuctionPriceWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	defer auctionRepository.PriceClock.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithAuctionType(auction_entity.Dutch),
+		auction_entity.WithStartingPrice(100),
+		auction_entity.WithPriceDrop(10, time.Minute))
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	bid, _ := bid_entity.CreateBid(uuid.New().String(), auctionEntity.Id, 150)
+	if ierr := bidRepository.ValidateBid(ctx, *bid); ierr == nil {
+		t.Errorf("Lance comum em leilão holandês deveria ser recusado")
+	}
+
+	winnerId := uuid.New().String()
+	winningBid, ierr := bidRepository.AcceptAuctionPrice(ctx, auctionEntity.Id, winnerId)
+	if ierr != nil {
+		t.Fatalf("Erro ao aceitar o preço: %v", ierr)
+	}
+	if winningBid.UserId != winnerId || winningBid.Amount != 100 {
+		t.Errorf("Lance vencedor deveria ser de %s por 100, obtido: %+v", winnerId, winningBid)
+	}
+
+	if _, ierr := bidRepository.AcceptAuctionPrice(ctx, auctionEntity.Id, uuid.New().String()); ierr == nil {
+		t.Errorf("Segunda aceitação deveria ser recusada")
+	}
+
+	foundAuction, ierr := auctionRepository.FindAuctionById(ctx, auctionEntity.Id)
+	if ierr != nil || foundAuction.Status != auction_entity.Completed {
+		t.Errorf("Leilão deveria estar encerrado, obtido: %+v (erro: %v)", foundAuction, ierr)
+	}
+}
+
+// Teste do leilão holandês com o relógio de preços atrasado: o vencedor paga
+// o preço vigente, não o último publicado
+func TestAcceptAuctionPriceWithClockBehindWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	defer auctionRepository.PriceClock.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithAuctionType(auction_entity.Dutch),
+		auction_entity.WithStartingPrice(100),
+		auction_entity.WithPriceDrop(10, time.Minute))
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	// Duas quedas já deveriam ter acontecido, mas current_price continua 100
+	startsAt := time.Now().Add(-150 * time.Second).Unix()
+	if _, err := auctionRepository.Collection.UpdateByID(ctx, auctionEntity.Id,
+		bson.M{"$set": bson.M{"starts_at": startsAt}}); err != nil {
+		t.Fatalf("Erro ao atrasar o relógio de preços: %v", err)
+	}
+
+	winningBid, ierr := bidRepository.AcceptAuctionPrice(ctx, auctionEntity.Id, uuid.New().String())
+	if ierr != nil {
+		t.Fatalf("Erro ao aceitar o preço: %v", ierr)
+	}
+	if winningBid.Amount != 80 {
+		t.Errorf("Vencedor deveria pagar o preço vigente 80, obtido: %v", winningBid.Amount)
+	}
+
+	foundAuction, ierr := auctionRepository.FindAuctionById(ctx, auctionEntity.Id)
+	if ierr != nil || foundAuction.CurrentPrice != 80 {
+		t.Errorf("Preço gravado deveria ser o pago, obtido: %+v (erro: %v)", foundAuction, ierr)
+	}
+}
+
+// Teste do leilão reverso: lances precisam diminuir e vence o menor
+func TestReverseAuctionWinnerWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithAuctionType(auction_entity.Reverse),
+		auction_entity.WithStartingPrice(1000))
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	newBid := func(amount float64) bid_entity.Bid {
+		bid, _ := bid_entity.CreateBid(uuid.New().String(), auctionEntity.Id, amount)
+		return *bid
+	}
+
+	first, tooHigh, lowest := newBid(900), newBid(950), newBid(850)
+	results, ierr := bidRepository.CreateBid(ctx, []bid_entity.Bid{first, tooHigh, lowest})
+	if ierr != nil {
+		t.Fatalf("Erro ao inserir lote: %v", ierr)
+	}
+
+	if results[1].Status != bid_entity.Rejected || results[1].Reason != bid_entity.BidTooHigh {
+		t.Errorf("Lance acima do menor lance deveria ser recusado, obtido: %+v", results[1])
+	}
+
+	winningBid, ierr := bidRepository.FindWinningBidByAuctionId(ctx, auctionEntity.Id)
+	if ierr != nil || winningBid.Id != lowest.Id {
+		t.Errorf("Vencedor deveria ser o menor lance %s, obtido: %+v (erro: %v)", lowest.Id, winningBid, ierr)
+	}
+}
+
+// Teste do leilão fechado: dois lances do mesmo usuário no mesmo lote, e só o
+// mais recente continua valendo
+func TestSealedBidsOfSameUserInOneBatchWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithAuctionType(auction_entity.SealedFirstPrice))
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	userId := uuid.New().String()
+	first, _ := bid_entity.CreateBid(userId, auctionEntity.Id, 100)
+	first.Timestamp = first.Timestamp.Add(-2 * time.Second)
+	last, _ := bid_entity.CreateBid(userId, auctionEntity.Id, 120)
+
+	results, ierr := bidRepository.CreateBid(ctx, []bid_entity.Bid{*first, *last})
+	if ierr != nil {
+		t.Fatalf("Erro ao inserir lote: %v", ierr)
+	}
+	for i, result := range results {
+		if result.Status != bid_entity.Accepted {
+			t.Errorf("Lance %d deveria ser aceito, obtido: %+v", i, result)
+		}
+	}
+
+	foundFirst, ierr := bidRepository.FindBidById(ctx, first.Id)
+	if ierr != nil || foundFirst.Status != bid_entity.Replaced {
+		t.Errorf("Primeiro lance deveria ser substituído: %+v (erro: %v)", foundFirst, ierr)
+	}
+
+	foundLast, ierr := bidRepository.FindBidById(ctx, last.Id)
+	if ierr != nil || foundLast.Status != bid_entity.Accepted {
+		t.Errorf("Último lance deveria continuar valendo: %+v (erro: %v)", foundLast, ierr)
+	}
+
+	winningBid, ierr := bidRepository.FindWinningBidByAuctionId(ctx, auctionEntity.Id)
+	if ierr != nil || winningBid.Id != last.Id {
+		t.Errorf("Vencedor deveria ser o último lance %s, obtido: %+v (erro: %v)", last.Id, winningBid, ierr)
+	}
+}
+
+// Teste da compra imediata: encerra o leilão na hora e o status em cache é
+// descartado, então lances seguintes são recusados como leilão fechado
+func TestBuyAuctionNowWithMongoDB(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://mongodb-test:27017"))
+	if err != nil {
+		t.Skip("MongoDB não disponível para teste - use Docker Compose")
+		return
+	}
+	defer client.Disconnect(ctx)
+
+	database := client.Database("test_auction_db")
+	defer database.Drop(ctx)
+
+	auctionRepository := auction.NewAuctionRepository(database)
+	defer auctionRepository.Scheduler.Stop()
+	bidRepository := NewBidRepository(database, auctionRepository)
+
+	auctionEntity, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithBuyNowPrice(500))
+	if err := auctionRepository.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Erro ao salvar leilão: %v", err)
+	}
+
+	newBid := func(amount float64) bid_entity.Bid {
+		bid, _ := bid_entity.CreateBid(uuid.New().String(), auctionEntity.Id, amount)
+		return *bid
+	}
+
+	if _, ierr := bidRepository.CreateBid(ctx, []bid_entity.Bid{newBid(100)}); ierr != nil {
+		t.Fatalf("Erro ao inserir lance: %v", ierr)
+	}
+
+	buyerId := uuid.New().String()
+	purchase, ierr := bidRepository.BuyAuctionNow(ctx, auctionEntity.Id, buyerId)
+	if ierr != nil {
+		t.Fatalf("Erro na compra imediata: %v", ierr)
+	}
+	if purchase.UserId != buyerId || purchase.Amount != 500 {
+		t.Errorf("Compra deveria ser de %s por 500, obtida: %+v", buyerId, purchase)
+	}
+
+	results, ierr := bidRepository.CreateBid(ctx, []bid_entity.Bid{newBid(600)})
+	if ierr != nil || results[0].Reason != bid_entity.AuctionClosed {
+		t.Errorf("Lance após a compra deveria ser recusado como leilão fechado, obtido: %+v (erro: %v)", results, ierr)
+	}
+
+	winningBid, ierr := bidRepository.FindWinningBidByAuctionId(ctx, auctionEntity.Id)
+	if ierr != nil || winningBid.UserId != buyerId {
+		t.Errorf("Vencedor deveria ser o comprador, obtido: %+v (erro: %v)", winningBid, ierr)
+	}
+}
@@ -0,0 +1,124 @@
+package bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// AcceptAuctionPrice encerra o leilão holandês em nome do usuário pelo preço
+// vigente no instante do encerramento e grava o lance vencedor. Entre as
+// quedas do relógio de preços o current_price gravado pode estar acima do
+// preço vigente; CompleteAuction o corrige no próprio encerramento.
+func (bd *BidRepository) AcceptAuctionPrice(
+	ctx context.Context, auctionId, userId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	unlock := bd.lockAuction(auctionId)
+	defer unlock()
+
+	auctionEntity, err := bd.findAuction(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auctionEntity.Type != auction_entity.Dutch {
+		return nil, internal_error.NewBadRequestError("Only dutch auctions accept the current price")
+	}
+
+	return bd.completeAuctionWithBid(ctx, auctionEntity, userId, func(completedAuction *auction_entity.Auction) float64 {
+		return completedAuction.CurrentPrice
+	})
+}
+
+// BuyAuctionNow encerra o leilão pelo preço de compra imediata, com o
+// comprador como vencedor. A compra só é possível enquanto o maior lance
+// está abaixo desse preço.
+func (bd *BidRepository) BuyAuctionNow(
+	ctx context.Context, auctionId, userId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	unlock := bd.lockAuction(auctionId)
+	defer unlock()
+
+	auctionEntity, err := bd.findAuction(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auctionEntity.AcceptsBuyNow() {
+		return nil, internal_error.NewBadRequestError("Auction has no buy-it-now price")
+	}
+
+	highest, err := bd.findHighestBid(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if highest.Amount >= auctionEntity.BuyNowPrice {
+		return nil, internal_error.NewBadRequestError("Buy-it-now is no longer available for this auction")
+	}
+
+	return bd.completeAuctionWithBid(ctx, auctionEntity, userId, func(completedAuction *auction_entity.Auction) float64 {
+		return completedAuction.BuyNowPrice
+	})
+}
+
+// completeAuctionWithBid encerra o leilão e grava o lance do usuário como
+// vencedor, com o valor que amount extrai do leilão como ele ficou no
+// instante do encerramento. O encerramento é condicional no banco, então só o
+// primeiro comprador vence, mesmo com várias instâncias; se o lance não puder
+// ser gravado o leilão é reaberto. Deve ser chamado com o leilão travado.
+func (bd *BidRepository) completeAuctionWithBid(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction,
+	userId string,
+	amount func(completedAuction *auction_entity.Auction) float64) (*bid_entity.Bid, *internal_error.InternalError) {
+	now := time.Now()
+	if _, err := checkAuctionWindow(auctionEntity, now); err != nil {
+		return nil, err
+	}
+
+	bidEntity, err := bid_entity.CreateBid(userId, auctionEntity.Id, amount(auctionEntity))
+	if err != nil {
+		return nil, err
+	}
+
+	completedAuction, err := bd.AuctionRepository.CompleteAuction(ctx, auctionEntity, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAuction == nil {
+		return nil, internal_error.NewBadRequestError("Auction is closed")
+	}
+
+	bidEntity.Amount = amount(completedAuction)
+	bidEntity.Status = bid_entity.Accepted
+
+	evaluatedBids, err := bd.persistBids(ctx, []evaluatedBid{{
+		Bid:    *bidEntity,
+		Result: bid_entity.NewAcceptedBidResult(bidEntity.Id),
+	}})
+	if err == nil && evaluatedBids[0].Result.Status != bid_entity.Accepted {
+		err = internal_error.NewInternalServerError("Error trying to insert bid")
+	}
+
+	if err != nil {
+		if reopenErr := bd.AuctionRepository.UpdateAuctionStatus(
+			ctx, auctionEntity.Id, auction_entity.Active); reopenErr != nil {
+			logger.Error(fmt.Sprintf("Error trying to reopen auction %s", auctionEntity.Id), reopenErr)
+		} else if completedAuction.Type == auction_entity.Dutch {
+			bd.AuctionRepository.PriceClock.Track(completedAuction)
+		}
+		return nil, err
+	}
+
+	bd.auctionHighestBidMutex.Lock()
+	bd.auctionHighestBidMap[auctionEntity.Id] = highestBid{Amount: bidEntity.Amount, UserId: bidEntity.UserId}
+	bd.auctionHighestBidMutex.Unlock()
+
+	bd.AuctionRepository.NotifyAuctionClosed(ctx, auctionEntity.Id)
+
+	return bidEntity, nil
+}
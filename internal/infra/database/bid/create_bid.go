@@ -2,119 +2,686 @@ package bid
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/infra/database/auction"
 	"fullcycle-auction_go/internal/internal_error"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type BidEntityMongo struct {
-	Id        string  `bson:"_id"`
-	UserId    string  `bson:"user_id"`
-	AuctionId string  `bson:"auction_id"`
-	Amount    float64 `bson:"amount"`
-	Timestamp int64   `bson:"timestamp"`
+	Id               string               `bson:"_id"`
+	UserId           string               `bson:"user_id"`
+	AuctionId        string               `bson:"auction_id"`
+	Amount           float64              `bson:"amount"`
+	Quantity         int                  `bson:"quantity,omitempty"`
+	Timestamp        int64                `bson:"timestamp"`
+	Status           bid_entity.BidStatus `bson:"status"`
+	RejectionReason  string               `bson:"rejection_reason,omitempty"`
+	RejectionMessage string               `bson:"rejection_message,omitempty"`
 }
 
+// BidRepository mantém em memória as regras de cada leilão (auctionMap), o
+// status e o término, que mudam durante o leilão e ficam em mapas próprios, e
+// o maior lance aceito, usado para validar os incrementos mínimos
 type BidRepository struct {
-	Collection            *mongo.Collection
-	AuctionRepository     *auction.AuctionRepository
-	auctionInterval       time.Duration
-	auctionStatusMap      map[string]auction_entity.AuctionStatus
-	auctionEndTimeMap     map[string]time.Time
-	auctionStatusMapMutex *sync.Mutex
-	auctionEndTimeMutex   *sync.Mutex
+	Collection             *mongo.Collection
+	ProxyCollection        *mongo.Collection
+	AuctionRepository      *auction.AuctionRepository
+	auctionMap             map[string]auction_entity.Auction
+	auctionStatusMap       map[string]auction_entity.AuctionStatus
+	auctionEndTimeMap      map[string]time.Time
+	auctionHighestBidMap   map[string]highestBid
+	auctionMapMutex        *sync.Mutex
+	auctionStatusMapMutex  *sync.Mutex
+	auctionEndTimeMutex    *sync.Mutex
+	auctionHighestBidMutex *sync.Mutex
+	softCloseWindow        time.Duration
+	softCloseExtension     time.Duration
+	auctionLocks           map[string]*sync.Mutex
+	auctionLocksMutex      *sync.Mutex
 }
 
 func NewBidRepository(database *mongo.Database, auctionRepository *auction.AuctionRepository) *BidRepository {
-	return &BidRepository{
-		auctionInterval:       getAuctionInterval(),
-		auctionStatusMap:      make(map[string]auction_entity.AuctionStatus),
-		auctionEndTimeMap:     make(map[string]time.Time),
-		auctionStatusMapMutex: &sync.Mutex{},
-		auctionEndTimeMutex:   &sync.Mutex{},
-		Collection:            database.Collection("bids"),
-		AuctionRepository:     auctionRepository,
+	bidRepository := &BidRepository{
+		auctionMap:             make(map[string]auction_entity.Auction),
+		auctionStatusMap:       make(map[string]auction_entity.AuctionStatus),
+		auctionEndTimeMap:      make(map[string]time.Time),
+		auctionHighestBidMap:   make(map[string]highestBid),
+		auctionMapMutex:        &sync.Mutex{},
+		auctionStatusMapMutex:  &sync.Mutex{},
+		auctionEndTimeMutex:    &sync.Mutex{},
+		auctionHighestBidMutex: &sync.Mutex{},
+		softCloseWindow:        getSoftCloseWindow(),
+		softCloseExtension:     getSoftCloseExtension(),
+		auctionLocks:           make(map[string]*sync.Mutex),
+		auctionLocksMutex:      &sync.Mutex{},
+		Collection:             database.Collection("bids"),
+		ProxyCollection:        database.Collection("proxy_bids"),
+		AuctionRepository:      auctionRepository,
 	}
+
+	auctionRepository.Scheduler.OnAuctionClosed(bidRepository.forgetAuctionStatus)
+	auctionRepository.Scheduler.OnAuctionPaused(bidRepository.forgetAuctionStatus)
+	auctionRepository.OnAuctionUpdated(bidRepository.forgetAuctionStatus)
+
+	return bidRepository
+}
+
+// ValidateBid verifica se o lance seria aceito agora, sem reservá-lo como
+// maior lance. A validação definitiva acontece no CreateBid.
+func (bd *BidRepository) ValidateBid(
+	ctx context.Context,
+	bidEntity bid_entity.Bid) *internal_error.InternalError {
+	auctionEntity, err := bd.findAuction(ctx, bidEntity.AuctionId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := checkAuctionWindow(auctionEntity, time.Now()); err != nil {
+		return err
+	}
+
+	if !auctionEntity.AcceptsBids() {
+		return internal_error.NewBadRequestError(dutchBidMessage)
+	}
+
+	if err := auctionEntity.ValidateBidQuantity(bidEntity.Quantity); err != nil {
+		return err
+	}
+
+	highest, err := bd.findHighestBid(ctx, bidEntity.AuctionId)
+	if err != nil {
+		return err
+	}
+
+	return auctionEntity.ValidateBidAmount(bidEntity.Amount, highest.Amount)
+}
+
+// evaluatedBid é um lance já avaliado, pronto para ser gravado. SoftClose
+// indica que o lance caiu na janela final do leilão e ReplacesPrevious que
+// ele substitui os lances anteriores do usuário (leilões fechados).
+type evaluatedBid struct {
+	Bid              bid_entity.Bid
+	Result           bid_entity.BidResult
+	SoftClose        bool
+	ReplacesPrevious bool
 }
 
+// CreateBid avalia o lote contra o estado dos leilões e o persiste com um
+// único BulkWrite não ordenado, devolvendo um resultado por lance na mesma
+// ordem da entrada. Leilões diferentes são avaliados em paralelo, mas os
+// lances de um mesmo leilão seguem a ordem de chegada, para que o maior lance,
+// os lances automáticos e o soft close sejam aplicados na sequência em que os
+// lances foram feitos. Os leilões do lote ficam travados até a gravação, para
+// que uma edição (LockAuctionBids) não aconteça entre a avaliação e a escrita.
+// Falhas de escrita de um lance voltam no resultado dele; o erro só é
+// retornado quando o lote inteiro não pôde ser gravado.
 func (bd *BidRepository) CreateBid(
 	ctx context.Context,
-	bidEntities []bid_entity.Bid) *internal_error.InternalError {
+	bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	if len(bidEntities) == 0 {
+		return []bid_entity.BidResult{}, nil
+	}
+
+	evaluatedBids := make([]evaluatedBid, len(bidEntities))
+
+	partitions := make(map[string][]int)
+	for i, bid := range bidEntities {
+		partitions[bid.AuctionId] = append(partitions[bid.AuctionId], i)
+	}
+
+	// Travados sempre na mesma ordem, para que lotes concorrentes com os
+	// mesmos leilões não esperem um pelo outro
+	auctionIds := make([]string, 0, len(partitions))
+	for auctionId := range partitions {
+		auctionIds = append(auctionIds, auctionId)
+	}
+	sort.Strings(auctionIds)
+	for _, auctionId := range auctionIds {
+		unlock := bd.lockAuction(auctionId)
+		defer unlock()
+	}
+
+	var automaticBids []evaluatedBid
+	automaticBidsMutex := &sync.Mutex{}
+
 	var wg sync.WaitGroup
-	for _, bid := range bidEntities {
+	for auctionId, indexes := range partitions {
 		wg.Add(1)
-		go func(bidValue bid_entity.Bid) {
+		go func(auctionId string, indexes []int) {
 			defer wg.Done()
 
-			bd.auctionStatusMapMutex.Lock()
-			auctionStatus, okStatus := bd.auctionStatusMap[bidValue.AuctionId]
-			bd.auctionStatusMapMutex.Unlock()
-
-			bd.auctionEndTimeMutex.Lock()
-			auctionEndTime, okEndTime := bd.auctionEndTimeMap[bidValue.AuctionId]
-			bd.auctionEndTimeMutex.Unlock()
+			var proxyBids []bid_entity.ProxyBid
+			proxyBidsLoaded := false
 
-			bidEntityMongo := &BidEntityMongo{
-				Id:        bidValue.Id,
-				UserId:    bidValue.UserId,
-				AuctionId: bidValue.AuctionId,
-				Amount:    bidValue.Amount,
-				Timestamp: bidValue.Timestamp.Unix(),
-			}
+			for _, index := range indexes {
+				evaluatedBids[index] = bd.evaluateBid(ctx, bidEntities[index])
 
-			if okEndTime && okStatus {
-				now := time.Now()
-				if auctionStatus == auction_entity.Completed || now.After(auctionEndTime) {
-					return
+				if evaluatedBids[index].Result.Status != bid_entity.Accepted {
+					continue
 				}
 
-				if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-					logger.Error("Error trying to insert bid", err)
-					return
+				if !proxyBidsLoaded {
+					proxyBids = bd.findProxyBids(ctx, auctionId)
+					proxyBidsLoaded = true
 				}
 
-				return
+				if automaticBid, ok := bd.placeProxyBid(ctx, auctionId, proxyBids); ok {
+					automaticBidsMutex.Lock()
+					automaticBids = append(automaticBids, automaticBid)
+					automaticBidsMutex.Unlock()
+				}
 			}
+		}(auctionId, indexes)
+	}
+	wg.Wait()
 
-			auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
-			if err != nil {
-				logger.Error("Error trying to find auction by id", err)
-				return
-			}
-			if auctionEntity.Status == auction_entity.Completed {
-				return
-			}
+	evaluatedBids, err := bd.persistBids(ctx, append(evaluatedBids, automaticBids...))
+	if err != nil {
+		return nil, err
+	}
 
-			bd.auctionStatusMapMutex.Lock()
-			bd.auctionStatusMap[bidValue.AuctionId] = auctionEntity.Status
-			bd.auctionStatusMapMutex.Unlock()
+	results := make([]bid_entity.BidResult, len(bidEntities))
+	for i := range bidEntities {
+		results[i] = evaluatedBids[i].Result
+	}
 
-			bd.auctionEndTimeMutex.Lock()
-			bd.auctionEndTimeMap[bidValue.AuctionId] = auctionEntity.Timestamp.Add(bd.auctionInterval)
-			bd.auctionEndTimeMutex.Unlock()
+	return results, nil
+}
 
-			if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-				logger.Error("Error trying to insert bid", err)
-				return
-			}
-		}(bid)
+// persistBids grava os lances avaliados de uma vez. Um lance cuja escrita
+// falhou fica recusado com ProcessingFailed (e, se tinha sido aceito, o maior
+// lance do leilão é recarregado do banco); os aceitos e gravados aplicam o
+// soft close.
+func (bd *BidRepository) persistBids(
+	ctx context.Context, evaluatedBids []evaluatedBid) ([]evaluatedBid, *internal_error.InternalError) {
+	models := make([]mongo.WriteModel, len(evaluatedBids))
+	for i, evaluated := range evaluatedBids {
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": evaluated.Bid.Id, "status": bid_entity.Queued}).
+			SetReplacement(newBidEntityMongo(evaluated.Bid, evaluated.Result)).
+			SetUpsert(true)
 	}
-	wg.Wait()
+
+	failedIndexes, err := bd.writeBids(ctx, models)
+	if err != nil {
+		for _, evaluated := range evaluatedBids {
+			bd.forgetHighestBid(evaluated.Bid.AuctionId)
+		}
+		return nil, err
+	}
+
+	for index := range failedIndexes {
+		bd.markProcessingFailed(ctx, evaluatedBids[index].Bid.Id)
+
+		if evaluatedBids[index].Result.Status != bid_entity.Accepted {
+			continue
+		}
+
+		bd.forgetHighestBid(evaluatedBids[index].Bid.AuctionId)
+		evaluatedBids[index].Result = bid_entity.NewRejectedBidResult(
+			evaluatedBids[index].Bid.Id, bid_entity.ProcessingFailed, "Error trying to insert bid")
+	}
+
+	for _, evaluated := range evaluatedBids {
+		if evaluated.Result.Status != bid_entity.Accepted {
+			continue
+		}
+
+		if evaluated.ReplacesPrevious {
+			bd.replacePreviousBids(ctx, evaluated.Bid)
+		}
+
+		if evaluated.SoftClose {
+			bd.extendAuctionEndTime(ctx, evaluated.Bid.AuctionId, bidTime(evaluated.Bid))
+		}
+	}
+
+	return evaluatedBids, nil
+}
+
+// evaluateBid decide se o lance é aceito, reservando-o como maior lance do
+// leilão, e indica se ele caiu na janela do soft close. Em leilões fechados
+// basta respeitar o preço inicial e o lance substitui os anteriores do
+// usuário; nos de várias unidades também basta o preço inicial, já que os
+// vencedores só são apurados no fim. A janela do leilão e o soft close são
+// avaliados no instante do lance (bidTime), não no do processamento; o
+// agendador processa os lances enfileirados do leilão antes de fechá-lo, então
+// o resultado não depende do BATCH_INSERT_INTERVAL. Nada é gravado aqui.
+func (bd *BidRepository) evaluateBid(
+	ctx context.Context, bidValue bid_entity.Bid) evaluatedBid {
+	rejected := func(reason bid_entity.RejectionReason, message string) evaluatedBid {
+		return evaluatedBid{
+			Bid:    bidValue,
+			Result: bid_entity.NewRejectedBidResult(bidValue.Id, reason, message),
+		}
+	}
+
+	auctionEntity, err := bd.findAuction(ctx, bidValue.AuctionId)
+	if err != nil {
+		if err.Err == "not_found" {
+			return rejected(bid_entity.AuctionNotFound, err.Error())
+		}
+
+		logger.Error("Error trying to find auction by id", err)
+		return rejected(bid_entity.ProcessingFailed, err.Error())
+	}
+
+	now := bidTime(bidValue)
+	if reason, err := checkAuctionWindow(auctionEntity, now); err != nil {
+		return rejected(reason, err.Error())
+	}
+
+	if !auctionEntity.AcceptsBids() {
+		return rejected(bid_entity.BidNotAllowed, dutchBidMessage)
+	}
+
+	if err := auctionEntity.ValidateBidQuantity(bidValue.Quantity); err != nil {
+		return rejected(bid_entity.InvalidQuantity, rejectionMessage(err))
+	}
+
+	if auctionEntity.IsSealed() {
+		if err := auctionEntity.ValidateBidAmount(bidValue.Amount, 0); err != nil {
+			return rejected(bid_entity.BidTooLow, rejectionMessage(err))
+		}
+
+		return evaluatedBid{
+			Bid:              bidValue,
+			Result:           bid_entity.NewAcceptedBidResult(bidValue.Id),
+			ReplacesPrevious: true,
+		}
+	}
+
+	if auctionEntity.IsMultiUnit() {
+		if err := auctionEntity.ValidateBidAmount(bidValue.Amount, 0); err != nil {
+			return rejected(bid_entity.BidTooLow, rejectionMessage(err))
+		}
+
+		return evaluatedBid{
+			Bid:       bidValue,
+			Result:    bid_entity.NewAcceptedBidResult(bidValue.Id),
+			SoftClose: auctionEntity.EndsAt.Sub(now) <= bd.softCloseWindow,
+		}
+	}
+
+	if err := bd.reserveHighestBid(ctx, auctionEntity, bidValue); err != nil {
+		if err.Err != "bad_request" {
+			return rejected(bid_entity.ProcessingFailed, err.Error())
+		}
+
+		if auctionEntity.IsReverse() {
+			return rejected(bid_entity.BidTooHigh, rejectionMessage(err))
+		}
+
+		return rejected(bid_entity.BidTooLow, rejectionMessage(err))
+	}
+
+	return evaluatedBid{
+		Bid:       bidValue,
+		Result:    bid_entity.NewAcceptedBidResult(bidValue.Id),
+		SoftClose: auctionEntity.EndsAt.Sub(now) <= bd.softCloseWindow,
+	}
+}
+
+const dutchBidMessage = "Dutch auctions only accept the current price"
+
+// bidTime é o instante em que o lance foi feito, usado na janela do leilão e
+// no soft close
+func bidTime(bidValue bid_entity.Bid) time.Time {
+	if bidValue.Timestamp.IsZero() {
+		return time.Now()
+	}
+
+	return bidValue.Timestamp
+}
+
+func rejectionMessage(err *internal_error.InternalError) string {
+	if len(err.Causes) == 0 {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s %s", err.Error(), err.Causes[0].Field, err.Causes[0].Message)
+}
+
+// replacePreviousBids marca como substituídos os lances aceitos anteriores
+// do usuário no leilão, deixando valer apenas o último. Só são substituídos os
+// lances mais antigos que este (no mesmo segundo, o de id menor), para que
+// dois lances do usuário gravados no mesmo lote não substituam um ao outro.
+func (bd *BidRepository) replacePreviousBids(ctx context.Context, bidValue bid_entity.Bid) {
+	timestamp := bidValue.Timestamp.Unix()
+	filter := bson.M{
+		"auction_id": bidValue.AuctionId,
+		"user_id":    bidValue.UserId,
+		"status":     bson.M{"$nin": bson.A{bid_entity.Queued, bid_entity.Rejected, bid_entity.Replaced}},
+		"$or": bson.A{
+			bson.M{"timestamp": bson.M{"$lt": timestamp}},
+			bson.M{"timestamp": timestamp, "_id": bson.M{"$lt": bidValue.Id}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"status": bid_entity.Replaced}}
+
+	if _, err := bd.Collection.UpdateMany(ctx, filter, update); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to replace previous bids of user %s", bidValue.UserId), err)
+	}
+}
+
+// writeBids grava os lances com um BulkWrite não ordenado e devolve os
+// índices que falharam. Cada lance substitui o seu documento Queued ou é
+// inserido; um lance que já tem resultado gravado não é sobrescrito, porque
+// o upsert falha por chave duplicada. O erro só é retornado quando a falha
+// não é de documentos específicos (ex: conexão perdida ou write concern não
+// atendido).
+func (bd *BidRepository) writeBids(
+	ctx context.Context, models []mongo.WriteModel) (map[int]struct{}, *internal_error.InternalError) {
+	_, err := bd.Collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err == nil {
+		return nil, nil
+	}
+
+	var bulkWriteException mongo.BulkWriteException
+	if !errors.As(err, &bulkWriteException) || len(bulkWriteException.WriteErrors) == 0 {
+		logger.Error("Error trying to insert bid batch", err)
+		return nil, internal_error.NewInternalServerError("Error trying to insert bids")
+	}
+
+	failedIndexes := make(map[int]struct{})
+	for _, writeError := range bulkWriteException.WriteErrors {
+		logger.Error(fmt.Sprintf("Error trying to insert bid at batch index %d", writeError.Index), writeError)
+		failedIndexes[writeError.Index] = struct{}{}
+	}
+
+	return failedIndexes, nil
+}
+
+// markProcessingFailed grava como recusado o lance enfileirado cuja escrita
+// do resultado falhou, para que a consulta de status não o mostre na fila
+// para sempre
+func (bd *BidRepository) markProcessingFailed(ctx context.Context, bidId string) {
+	filter := bson.M{"_id": bidId, "status": bid_entity.Queued}
+	update := bson.M{"$set": bson.M{
+		"status":            bid_entity.Rejected,
+		"rejection_reason":  bid_entity.ProcessingFailed,
+		"rejection_message": "Error trying to insert bid",
+	}}
+
+	if _, err := bd.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to mark bid %s as failed", bidId), err)
+	}
+}
+
+func newBidEntityMongo(bidValue bid_entity.Bid, result bid_entity.BidResult) *BidEntityMongo {
+	return &BidEntityMongo{
+		Id:               bidValue.Id,
+		UserId:           bidValue.UserId,
+		AuctionId:        bidValue.AuctionId,
+		Amount:           bidValue.Amount,
+		Quantity:         bidValue.Quantity,
+		Timestamp:        bidValue.Timestamp.Unix(),
+		Status:           result.Status,
+		RejectionReason:  string(result.Reason),
+		RejectionMessage: result.Message,
+	}
+}
+
+func (bm *BidEntityMongo) toEntity() *bid_entity.Bid {
+	quantity := bm.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	return &bid_entity.Bid{
+		Id:               bm.Id,
+		UserId:           bm.UserId,
+		AuctionId:        bm.AuctionId,
+		Amount:           bm.Amount,
+		Quantity:         quantity,
+		Timestamp:        time.Unix(bm.Timestamp, 0),
+		Status:           bm.Status,
+		RejectionReason:  bid_entity.RejectionReason(bm.RejectionReason),
+		RejectionMessage: bm.RejectionMessage,
+	}
+}
+
+// checkAuctionWindow verifica se o leilão aceitava lances no instante
+// informado. O status em cache de um leilão agendado não é atualizado na
+// abertura, por isso a janela de horários é quem decide.
+func checkAuctionWindow(
+	auctionEntity *auction_entity.Auction,
+	now time.Time) (bid_entity.RejectionReason, *internal_error.InternalError) {
+	if auctionEntity.Status == auction_entity.Paused {
+		return bid_entity.AuctionPaused, internal_error.NewBadRequestError("Auction is paused")
+	}
+
+	if auctionEntity.Status == auction_entity.Draft {
+		return bid_entity.AuctionNotOpen, internal_error.NewBadRequestError("Auction is not published yet")
+	}
+
+	if (auctionEntity.Status != auction_entity.Active && auctionEntity.Status != auction_entity.Scheduled) ||
+		now.After(auctionEntity.EndsAt) {
+		return bid_entity.AuctionClosed, internal_error.NewBadRequestError("Auction is closed")
+	}
+
+	if now.Before(auctionEntity.StartsAt) {
+		return bid_entity.AuctionNotOpen, internal_error.NewBadRequestError("Auction is not open for bids yet")
+	}
+
+	return "", nil
+}
+
+// findAuction retorna o leilão com status e término atualizados, usando os
+// mapas em memória e consultando o repositório de leilões apenas na primeira
+// vez que o leilão é visto
+func (bd *BidRepository) findAuction(
+	ctx context.Context,
+	auctionId string) (*auction_entity.Auction, *internal_error.InternalError) {
+	bd.auctionMapMutex.Lock()
+	auctionEntity, okAuction := bd.auctionMap[auctionId]
+	bd.auctionMapMutex.Unlock()
+
+	bd.auctionStatusMapMutex.Lock()
+	auctionStatus, okStatus := bd.auctionStatusMap[auctionId]
+	bd.auctionStatusMapMutex.Unlock()
+
+	bd.auctionEndTimeMutex.Lock()
+	auctionEndTime, okEndTime := bd.auctionEndTimeMap[auctionId]
+	bd.auctionEndTimeMutex.Unlock()
+
+	if okAuction && okStatus && okEndTime {
+		auctionEntity.Status = auctionStatus
+		auctionEntity.EndsAt = auctionEndTime
+		return &auctionEntity, nil
+	}
+
+	foundAuction, err := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	bd.auctionMapMutex.Lock()
+	bd.auctionMap[auctionId] = *foundAuction
+	bd.auctionMapMutex.Unlock()
+
+	bd.auctionStatusMapMutex.Lock()
+	bd.auctionStatusMap[auctionId] = foundAuction.Status
+	bd.auctionStatusMapMutex.Unlock()
+
+	bd.auctionEndTimeMutex.Lock()
+	bd.auctionEndTimeMap[auctionId] = foundAuction.EndsAt
+	bd.auctionEndTimeMutex.Unlock()
+
+	return foundAuction, nil
+}
+
+// highestBid é o melhor lance aceito de um leilão e quem o fez: o maior, ou o
+// menor nos leilões reversos
+type highestBid struct {
+	Amount float64
+	UserId string
+}
+
+func (bd *BidRepository) findHighestBid(
+	ctx context.Context, auctionId string) (highestBid, *internal_error.InternalError) {
+	bd.auctionHighestBidMutex.Lock()
+	highest, ok := bd.auctionHighestBidMap[auctionId]
+	bd.auctionHighestBidMutex.Unlock()
+
+	if ok {
+		return highest, nil
+	}
+
+	winningBid, err := bd.FindWinningBidByAuctionId(ctx, auctionId)
+	if err != nil {
+		if err.Err != "not_found" {
+			return highestBid{}, err
+		}
+
+		return highestBid{}, nil
+	}
+
+	return highestBid{Amount: winningBid.Amount, UserId: winningBid.UserId}, nil
+}
+
+// reserveHighestBid valida o valor contra o maior lance e o registra como o
+// novo maior lance na mesma seção crítica, para que lances concorrentes do
+// mesmo leilão não sejam aceitos com o mesmo valor de referência
+func (bd *BidRepository) reserveHighestBid(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction,
+	bidValue bid_entity.Bid) *internal_error.InternalError {
+	loaded, err := bd.findHighestBid(ctx, auctionEntity.Id)
+	if err != nil {
+		return err
+	}
+
+	bd.auctionHighestBidMutex.Lock()
+	defer bd.auctionHighestBidMutex.Unlock()
+
+	highest, ok := bd.auctionHighestBidMap[auctionEntity.Id]
+	if !ok {
+		highest = loaded
+	}
+
+	if err := auctionEntity.ValidateBidAmount(bidValue.Amount, highest.Amount); err != nil {
+		return err
+	}
+
+	bd.auctionHighestBidMap[auctionEntity.Id] = highestBid{Amount: bidValue.Amount, UserId: bidValue.UserId}
+
 	return nil
 }
 
-func getAuctionInterval() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
+// lockAuction serializa o processamento de lances de um leilão entre lotes
+// concorrentes e os registros de lances automáticos
+func (bd *BidRepository) lockAuction(auctionId string) func() {
+	bd.auctionLocksMutex.Lock()
+	auctionLock, ok := bd.auctionLocks[auctionId]
+	if !ok {
+		auctionLock = &sync.Mutex{}
+		bd.auctionLocks[auctionId] = auctionLock
+	}
+	bd.auctionLocksMutex.Unlock()
+
+	auctionLock.Lock()
+	return auctionLock.Unlock
+}
+
+// LockAuctionBids trava o processamento de lances do leilão e informa se ele
+// já recebeu algum lance, inclusive os ainda na fila. Enquanto travado nenhum
+// lance do leilão é enfileirado nem gravado, então uma edição gravada antes do
+// unlock não concorre com lances já confirmados ao cliente. Em caso de erro o
+// leilão já volta destravado.
+func (bd *BidRepository) LockAuctionBids(
+	ctx context.Context, auctionId string) (bool, func(), *internal_error.InternalError) {
+	unlock := bd.lockAuction(auctionId)
+
+	filter := bson.M{
+		"auction_id": auctionId,
+		"status":     bson.M{"$ne": bid_entity.Rejected},
+	}
+
+	count, err := bd.Collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
 	if err != nil {
-		return time.Minute * 5
+		unlock()
+		logger.Error(fmt.Sprintf("Error trying to count bids by auctionId %s", auctionId), err)
+		return false, nil, internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to count bids by auctionId %s", auctionId))
+	}
+
+	return count > 0, unlock, nil
+}
+
+// forgetHighestBid descarta o maior lance em memória para que ele seja
+// recarregado do banco, usado quando um lance reservado não é persistido
+func (bd *BidRepository) forgetHighestBid(auctionId string) {
+	bd.auctionHighestBidMutex.Lock()
+	delete(bd.auctionHighestBidMap, auctionId)
+	bd.auctionHighestBidMutex.Unlock()
+}
+
+// forgetAuctionStatus descarta o status em memória de um leilão fechado,
+// pausado, retomado ou editado, para que o próximo lance recarregue o leilão
+// (status, término e regras de preço) do banco. Os encerramentos pelo
+// horário já são barrados pelo término em cache, mas os antecipados (como a
+// compra imediata) e as pausas só são vistos assim.
+func (bd *BidRepository) forgetAuctionStatus(ctx context.Context, auctionId string) *internal_error.InternalError {
+	bd.auctionStatusMapMutex.Lock()
+	delete(bd.auctionStatusMap, auctionId)
+	bd.auctionStatusMapMutex.Unlock()
+
+	return nil
+}
+
+// extendAuctionEndTime aplica o soft close a um lance aceito perto do fim do
+// leilão, mantendo o mapa de términos em memória alinhado com o banco
+func (bd *BidRepository) extendAuctionEndTime(ctx context.Context, auctionId string, at time.Time) {
+	if bd.softCloseWindow <= 0 || bd.softCloseExtension <= 0 {
+		return
+	}
+
+	endsAt, err := bd.AuctionRepository.ExtendAuctionEndTime(
+		ctx, auctionId, at, bd.softCloseWindow, bd.softCloseExtension)
+	if err != nil {
+		logger.Error("Error trying to extend auction end time", err)
+		return
+	}
+
+	if endsAt == nil {
+		return
+	}
+
+	bd.auctionEndTimeMutex.Lock()
+	bd.auctionEndTimeMap[auctionId] = *endsAt
+	bd.auctionEndTimeMutex.Unlock()
+}
+
+// getSoftCloseWindow obtém a janela final em que lances estendem o leilão;
+// zero desativa o soft close
+func getSoftCloseWindow() time.Duration {
+	softCloseWindow := os.Getenv("SOFT_CLOSE_WINDOW")
+	duration, err := time.ParseDuration(softCloseWindow)
+	if err != nil || duration < 0 {
+		return 0
+	}
+
+	return duration
+}
+
+func getSoftCloseExtension() time.Duration {
+	softCloseExtension := os.Getenv("SOFT_CLOSE_EXTENSION")
+	duration, err := time.ParseDuration(softCloseExtension)
+	if err != nil || duration < 0 {
+		return 0
 	}
 
 	return duration
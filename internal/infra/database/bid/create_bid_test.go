@@ -0,0 +1,53 @@
+package bid
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Teste da janela do leilão no instante do lance: um lance feito antes do
+// término e processado depois (fila ou write-ahead log) continua válido
+func TestEvaluateBidUsesBidTimestamp(t *testing.T) {
+	auctionEntity, err := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithEndsAt(time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	// Simula o leilão terminado durante a queda
+	endsAt := time.Now().Add(-time.Minute)
+	auctionEntity.StartsAt = endsAt.Add(-time.Hour)
+
+	bd := &BidRepository{
+		auctionMap:             map[string]auction_entity.Auction{auctionEntity.Id: *auctionEntity},
+		auctionStatusMap:       map[string]auction_entity.AuctionStatus{auctionEntity.Id: auction_entity.Active},
+		auctionEndTimeMap:      map[string]time.Time{auctionEntity.Id: endsAt},
+		auctionHighestBidMap:   map[string]highestBid{auctionEntity.Id: {}},
+		auctionMapMutex:        &sync.Mutex{},
+		auctionStatusMapMutex:  &sync.Mutex{},
+		auctionEndTimeMutex:    &sync.Mutex{},
+		auctionHighestBidMutex: &sync.Mutex{},
+	}
+
+	newBid := func(amount float64, timestamp time.Time) bid_entity.Bid {
+		bid, _ := bid_entity.CreateBid(uuid.New().String(), auctionEntity.Id, amount)
+		bid.Timestamp = timestamp
+		return *bid
+	}
+
+	beforeEnd := bd.evaluateBid(context.Background(), newBid(100, endsAt.Add(-time.Second)))
+	if beforeEnd.Result.Status != bid_entity.Accepted {
+		t.Errorf("Lance feito antes do término deveria ser aceito: %+v", beforeEnd.Result)
+	}
+
+	afterEnd := bd.evaluateBid(context.Background(), newBid(200, endsAt.Add(time.Second)))
+	if afterEnd.Result.Status != bid_entity.Rejected || afterEnd.Result.Reason != bid_entity.AuctionClosed {
+		t.Errorf("Lance feito depois do término deveria ser recusado: %+v", afterEnd.Result)
+	}
+}
@@ -2,18 +2,60 @@ package bid
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/internal_error"
+
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"time"
 )
 
+func (bd *BidRepository) FindBidById(
+	ctx context.Context, bidId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	filter := bson.M{"_id": bidId}
+
+	var bidEntityMongo BidEntityMongo
+	if err := bd.Collection.FindOne(ctx, filter).Decode(&bidEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Bid not found with this id = %s", bidId))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find bid by id = %s", bidId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find bid by id")
+	}
+
+	return bidEntityMongo.toEntity(), nil
+}
+
+// validBidsFilter seleciona os lances que contam no leilão; lances
+// enfileirados, recusados, substituídos e anulados ficam no banco apenas para
+// a consulta de status
+func validBidsFilter(auctionId string) bson.M {
+	return bson.M{
+		"auction_id": auctionId,
+		"status": bson.M{"$nin": bson.A{
+			bid_entity.Queued, bid_entity.Rejected, bid_entity.Replaced, bid_entity.Voided}},
+	}
+}
+
+// FindBidByAuctionId lista os lances válidos do leilão (validBidsFilter). Em leilões
+// fechados (sealed) a lista fica vazia até o encerramento.
 func (bd *BidRepository) FindBidByAuctionId(
 	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
-	filter := bson.M{"auctionId": auctionId}
+	auctionEntity, auctionErr := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if auctionErr != nil && auctionErr.Err != "not_found" {
+		return nil, auctionErr
+	}
+
+	if auctionEntity != nil && !auctionEntity.BidsAreVisible() {
+		return []bid_entity.Bid{}, nil
+	}
+
+	filter := validBidsFilter(auctionId)
 
 	cursor, err := bd.Collection.Find(ctx, filter)
 	if err != nil {
@@ -33,34 +75,84 @@ func (bd *BidRepository) FindBidByAuctionId(
 
 	var bidEntities []bid_entity.Bid
 	for _, bidEntityMongo := range bidEntitiesMongo {
-		bidEntities = append(bidEntities, bid_entity.Bid{
-			Id:        bidEntityMongo.Id,
-			UserId:    bidEntityMongo.UserId,
-			AuctionId: bidEntityMongo.AuctionId,
-			Amount:    bidEntityMongo.Amount,
-			Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
-		})
+		bidEntities = append(bidEntities, *bidEntityMongo.toEntity())
 	}
 
 	return bidEntities, nil
 }
 
+// FindWinningBidByAuctionId retorna o maior lance válido, ou o menor nos
+// leilões reversos; em caso de empate vence o mais antigo
 func (bd *BidRepository) FindWinningBidByAuctionId(
 	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
-	filter := bson.M{"auction_id": auctionId}
+	filter := validBidsFilter(auctionId)
+
+	sort, sortErr := bd.winningBidSort(ctx, auctionId)
+	if sortErr != nil {
+		return nil, sortErr
+	}
 
 	var bidEntityMongo BidEntityMongo
-	opts := options.FindOne().SetSort(bson.D{{"amount", -1}})
+	opts := options.FindOne().SetSort(sort)
 	if err := bd.Collection.FindOne(ctx, filter, opts).Decode(&bidEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("No bids found for auctionId %s", auctionId))
+		}
+
 		logger.Error("Error trying to find the auction winner", err)
 		return nil, internal_error.NewInternalServerError("Error trying to find the auction winner")
 	}
 
-	return &bid_entity.Bid{
-		Id:        bidEntityMongo.Id,
-		UserId:    bidEntityMongo.UserId,
-		AuctionId: bidEntityMongo.AuctionId,
-		Amount:    bidEntityMongo.Amount,
-		Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
-	}, nil
+	return bidEntityMongo.toEntity(), nil
+}
+
+// FindTopBidsByAuctionId retorna os melhores lances válidos na ordem de
+// FindWinningBidByAuctionId
+func (bd *BidRepository) FindTopBidsByAuctionId(
+	ctx context.Context, auctionId string, limit int64) ([]bid_entity.Bid, *internal_error.InternalError) {
+	filter := validBidsFilter(auctionId)
+
+	sort, sortErr := bd.winningBidSort(ctx, auctionId)
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	opts := options.Find().SetSort(sort).SetLimit(limit)
+
+	cursor, err := bd.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find top bids by auctionId %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find top bids")
+	}
+
+	var bidEntitiesMongo []BidEntityMongo
+	if err := cursor.All(ctx, &bidEntitiesMongo); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find top bids by auctionId %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find top bids")
+	}
+
+	bidEntities := make([]bid_entity.Bid, 0, len(bidEntitiesMongo))
+	for _, bidEntityMongo := range bidEntitiesMongo {
+		bidEntities = append(bidEntities, *bidEntityMongo.toEntity())
+	}
+
+	return bidEntities, nil
+}
+
+// winningBidSort ordena os lances do melhor para o pior: valor decrescente,
+// ou crescente nos leilões reversos, e o mais antigo primeiro no empate
+func (bd *BidRepository) winningBidSort(
+	ctx context.Context, auctionId string) (bson.D, *internal_error.InternalError) {
+	auctionEntity, err := bd.findAuction(ctx, auctionId)
+	if err != nil && err.Err != "not_found" {
+		return nil, err
+	}
+
+	amountOrder := -1
+	if auctionEntity != nil && auctionEntity.IsReverse() {
+		amountOrder = 1
+	}
+
+	return bson.D{{Key: "amount", Value: amountOrder}, {Key: "timestamp", Value: 1}}, nil
 }
@@ -0,0 +1,233 @@
+package bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type ProxyBidEntityMongo struct {
+	Id        string  `bson:"_id"`
+	UserId    string  `bson:"user_id"`
+	AuctionId string  `bson:"auction_id"`
+	MaxAmount float64 `bson:"max_amount"`
+	Timestamp int64   `bson:"timestamp"`
+}
+
+// CreateProxyBid registra o teto do usuário (um por usuário e leilão; um novo
+// registro substitui o anterior) e dá o lance automático que a disputa entre
+// os tetos exigir. O teto precisa superar o lance mínimo atual, a menos que o
+// usuário já seja o maior lance, caso em que basta não ficar abaixo dele.
+func (bd *BidRepository) CreateProxyBid(
+	ctx context.Context,
+	proxyBid bid_entity.ProxyBid) (*bid_entity.Bid, *internal_error.InternalError) {
+	unlock := bd.lockAuction(proxyBid.AuctionId)
+	defer unlock()
+
+	auctionEntity, err := bd.findAuction(ctx, proxyBid.AuctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auctionEntity.AcceptsProxyBids() {
+		return nil, internal_error.NewBadRequestError("Proxy bidding is only available for single-unit english auctions")
+	}
+
+	if _, err := checkAuctionWindow(auctionEntity, time.Now()); err != nil {
+		return nil, err
+	}
+
+	highest, err := bd.findHighestBid(ctx, proxyBid.AuctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	minimumAmount := auctionEntity.MinimumBidAmount(highest.Amount)
+	if highest.UserId == proxyBid.UserId {
+		minimumAmount = highest.Amount
+	}
+
+	if proxyBid.MaxAmount < minimumAmount {
+		return nil, internal_error.NewBadRequestError("Maximum amount is too low", internal_error.Causes{
+			Field:   "max_amount",
+			Message: fmt.Sprintf("must be at least %.2f", minimumAmount),
+		})
+	}
+
+	if err := bd.upsertProxyBid(ctx, proxyBid); err != nil {
+		return nil, err
+	}
+
+	if automaticBid, ok := bd.placeProxyBid(ctx, proxyBid.AuctionId, bd.findProxyBids(ctx, proxyBid.AuctionId)); ok {
+		evaluatedBids, err := bd.persistBids(ctx, []evaluatedBid{automaticBid})
+		if err != nil {
+			return nil, err
+		}
+
+		if evaluatedBids[0].Result.Status != bid_entity.Accepted {
+			return nil, internal_error.NewInternalServerError("Error trying to insert automatic bid")
+		}
+	}
+
+	highest, err = bd.findHighestBid(ctx, proxyBid.AuctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bid_entity.Bid{
+		AuctionId: proxyBid.AuctionId,
+		UserId:    highest.UserId,
+		Amount:    highest.Amount,
+		Status:    bid_entity.Accepted,
+	}, nil
+}
+
+func (bd *BidRepository) upsertProxyBid(
+	ctx context.Context, proxyBid bid_entity.ProxyBid) *internal_error.InternalError {
+	filter := bson.M{"auction_id": proxyBid.AuctionId, "user_id": proxyBid.UserId}
+	update := bson.M{
+		"$set": bson.M{
+			"max_amount": proxyBid.MaxAmount,
+			"timestamp":  proxyBid.Timestamp.Unix(),
+		},
+		"$setOnInsert": bson.M{"_id": proxyBid.Id},
+	}
+
+	if _, err := bd.ProxyCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		logger.Error("Error trying to save proxy bid", err)
+		return internal_error.NewInternalServerError("Error trying to save proxy bid")
+	}
+
+	return nil
+}
+
+// findProxyBids retorna os tetos do leilão do maior para o menor; em caso de
+// empate, o registrado primeiro vem antes. Falhas são apenas registradas,
+// para não recusar lances manuais por causa dos automáticos.
+func (bd *BidRepository) findProxyBids(ctx context.Context, auctionId string) []bid_entity.ProxyBid {
+	opts := options.Find().SetSort(bson.D{{Key: "max_amount", Value: -1}, {Key: "timestamp", Value: 1}})
+
+	cursor, err := bd.ProxyCollection.Find(ctx, bson.M{"auction_id": auctionId}, opts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+		return nil
+	}
+	defer cursor.Close(ctx)
+
+	var proxyBidsMongo []ProxyBidEntityMongo
+	if err := cursor.All(ctx, &proxyBidsMongo); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+		return nil
+	}
+
+	proxyBids := make([]bid_entity.ProxyBid, 0, len(proxyBidsMongo))
+	for _, proxyBidMongo := range proxyBidsMongo {
+		proxyBids = append(proxyBids, bid_entity.ProxyBid{
+			Id:        proxyBidMongo.Id,
+			UserId:    proxyBidMongo.UserId,
+			AuctionId: proxyBidMongo.AuctionId,
+			MaxAmount: proxyBidMongo.MaxAmount,
+			Timestamp: time.Unix(proxyBidMongo.Timestamp, 0),
+		})
+	}
+
+	return proxyBids
+}
+
+// placeProxyBid avalia o lance automático que os tetos exigem diante do
+// maior lance atual. O lance avaliado já está reservado como maior lance e
+// precisa ser gravado pelo chamador.
+func (bd *BidRepository) placeProxyBid(
+	ctx context.Context,
+	auctionId string,
+	proxyBids []bid_entity.ProxyBid) (evaluatedBid, bool) {
+	if len(proxyBids) == 0 {
+		return evaluatedBid{}, false
+	}
+
+	auctionEntity, err := bd.findAuction(ctx, auctionId)
+	if err != nil || !auctionEntity.AcceptsProxyBids() {
+		return evaluatedBid{}, false
+	}
+
+	highest, err := bd.findHighestBid(ctx, auctionId)
+	if err != nil {
+		return evaluatedBid{}, false
+	}
+
+	userId, amount, ok := resolveProxyBids(auctionEntity, proxyBids, highest)
+	if !ok {
+		return evaluatedBid{}, false
+	}
+
+	bidEntity, err := bid_entity.CreateBid(userId, auctionId, amount)
+	if err != nil {
+		return evaluatedBid{}, false
+	}
+
+	automaticBid := bd.evaluateBid(ctx, *bidEntity)
+	if automaticBid.Result.Status != bid_entity.Accepted {
+		logger.Info("Automatic bid rejected",
+			zap.String("auction_id", auctionId),
+			zap.String("reason", string(automaticBid.Result.Reason)))
+		return evaluatedBid{}, false
+	}
+
+	return automaticBid, true
+}
+
+// resolveProxyBids resolve a disputa entre os tetos como no eBay: o maior
+// teto (o mais antigo em caso de empate) lidera e paga apenas o incremento
+// mínimo sobre o maior teto concorrente que ainda consegue cobrir o lance
+// atual, limitado ao próprio teto. Se houver preço de reserva ao alcance do
+// líder, o lance vai direto até ela. proxyBids deve estar ordenado por
+// findProxyBids. Retorna false quando nenhum lance automático é necessário.
+func resolveProxyBids(
+	auctionEntity *auction_entity.Auction,
+	proxyBids []bid_entity.ProxyBid,
+	highest highestBid) (string, float64, bool) {
+	if len(proxyBids) == 0 {
+		return "", 0, false
+	}
+
+	leader := proxyBids[0]
+	minimumAmount := auctionEntity.MinimumBidAmount(highest.Amount)
+	if leader.MaxAmount < minimumAmount {
+		return "", 0, false
+	}
+
+	rivalMaxAmount := 0.0
+	for _, proxyBid := range proxyBids[1:] {
+		if proxyBid.UserId != leader.UserId && proxyBid.MaxAmount >= minimumAmount {
+			rivalMaxAmount = math.Max(rivalMaxAmount, proxyBid.MaxAmount)
+		}
+	}
+
+	if leader.UserId == highest.UserId && rivalMaxAmount == 0 {
+		return "", 0, false
+	}
+
+	amount := minimumAmount
+	if rivalMaxAmount > 0 {
+		amount = math.Max(amount, rivalMaxAmount+auctionEntity.MinimumIncrement(rivalMaxAmount))
+	}
+	if auctionEntity.ReservePrice > 0 {
+		amount = math.Max(amount, auctionEntity.ReservePrice)
+	}
+
+	amount = math.Min(amount, leader.MaxAmount)
+	if amount <= highest.Amount {
+		return "", 0, false
+	}
+
+	return leader.UserId, amount, true
+}
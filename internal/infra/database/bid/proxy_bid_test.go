@@ -0,0 +1,65 @@
+package bid
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"testing"
+	"time"
+)
+
+// Teste da disputa entre tetos de lances automáticos
+func TestResolveProxyBids(t *testing.T) {
+	auctionEntity, err := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithStartingPrice(10),
+		auction_entity.WithMinIncrement(1))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	now := time.Now()
+	proxy := func(userId string, maxAmount float64, age time.Duration) bid_entity.ProxyBid {
+		return bid_entity.ProxyBid{UserId: userId, MaxAmount: maxAmount, Timestamp: now.Add(-age)}
+	}
+
+	testCases := []struct {
+		proxyBids      []bid_entity.ProxyBid
+		highest        highestBid
+		expectedUserId string
+		expectedAmount float64
+		description    string
+	}{
+		{nil, highestBid{}, "", 0, "sem tetos"},
+		{[]bid_entity.ProxyBid{proxy("a", 50, 0)}, highestBid{}, "a", 10, "primeiro lance pelo preço inicial"},
+		{[]bid_entity.ProxyBid{proxy("a", 50, 0)}, highestBid{Amount: 20, UserId: "m"}, "a", 21, "cobre lance manual com o incremento"},
+		{[]bid_entity.ProxyBid{proxy("a", 50, 0)}, highestBid{Amount: 20, UserId: "a"}, "", 0, "líder sem concorrente não sobe"},
+		{[]bid_entity.ProxyBid{proxy("a", 50, 0)}, highestBid{Amount: 50, UserId: "m"}, "", 0, "teto não cobre o lance atual"},
+		{[]bid_entity.ProxyBid{proxy("a", 50, 0), proxy("b", 30, 0)}, highestBid{Amount: 20, UserId: "a"}, "a", 31, "maior teto paga o segundo mais incremento"},
+		{[]bid_entity.ProxyBid{proxy("a", 50, 0), proxy("b", 49.5, 0)}, highestBid{Amount: 20, UserId: "b"}, "a", 50, "incremento limitado ao teto do líder"},
+		{[]bid_entity.ProxyBid{proxy("a", 40, time.Minute), proxy("b", 40, 0)}, highestBid{Amount: 20, UserId: "b"}, "a", 40, "empate vence o teto mais antigo"},
+		{[]bid_entity.ProxyBid{proxy("a", 50, 0), proxy("b", 15, 0)}, highestBid{Amount: 20, UserId: "m"}, "a", 21, "teto que não cobre o lance atual é ignorado"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			userId, amount, ok := resolveProxyBids(auctionEntity, tc.proxyBids, tc.highest)
+
+			if tc.expectedUserId == "" {
+				if ok {
+					t.Errorf("Nenhum lance automático esperado, obtido: %s %.2f", userId, amount)
+				}
+				return
+			}
+
+			if !ok || userId != tc.expectedUserId || amount != tc.expectedAmount {
+				t.Errorf("Lance automático esperado: %s %.2f, obtido: %s %.2f (%v)",
+					tc.expectedUserId, tc.expectedAmount, userId, amount, ok)
+			}
+		})
+	}
+
+	reserveAuction, _ := auction_entity.CreateAuction("Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithReservePrice(100))
+	if _, amount, _ := resolveProxyBids(reserveAuction, []bid_entity.ProxyBid{proxy("a", 150, 0)}, highestBid{}); amount != 100 {
+		t.Errorf("Lance automático deveria ir direto à reserva, obtido: %.2f", amount)
+	}
+}
@@ -0,0 +1,44 @@
+package bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// QueueBid grava o lance enfileirado com status Queued, para que a consulta
+// de status o encontre em qualquer instância e depois de um reinício. O lote
+// substitui o mesmo documento pelo resultado (persistBids). A gravação é feita
+// com o leilão travado, para que uma edição em andamento veja o lance.
+func (bd *BidRepository) QueueBid(
+	ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	unlock := bd.lockAuction(bidEntity.AuctionId)
+	defer unlock()
+
+	queued := newBidEntityMongo(bidEntity, bid_entity.BidResult{BidId: bidEntity.Id, Status: bid_entity.Queued})
+
+	if _, err := bd.Collection.InsertOne(ctx, queued); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to queue bid %s", bidEntity.Id), err)
+		return internal_error.NewInternalServerError("Error trying to queue bid")
+	}
+
+	return nil
+}
+
+// DiscardQueuedBid remove o lance gravado como Queued que não chegou à fila.
+// Lances já processados não são afetados.
+func (bd *BidRepository) DiscardQueuedBid(
+	ctx context.Context, bidId string) *internal_error.InternalError {
+	filter := bson.M{"_id": bidId, "status": bid_entity.Queued}
+
+	if _, err := bd.Collection.DeleteOne(ctx, filter); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to discard queued bid %s", bidId), err)
+		return internal_error.NewInternalServerError("Error trying to discard queued bid")
+	}
+
+	return nil
+}
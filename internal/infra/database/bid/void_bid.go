@@ -0,0 +1,48 @@
+package bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// VoidBidsByAuctionId marca como anulados os lances de um leilão cancelado e
+// remove os tetos de lances automáticos. Os lances recusados continuam como
+// estão, para que a consulta de status mostre o motivo original.
+func (bd *BidRepository) VoidBidsByAuctionId(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	unlock := bd.lockAuction(auctionId)
+	defer unlock()
+
+	filter := bson.M{
+		"auction_id": auctionId,
+		"status":     bson.M{"$ne": bid_entity.Rejected},
+	}
+	update := bson.M{"$set": bson.M{"status": bid_entity.Voided}}
+
+	result, err := bd.Collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to void bids by auctionId %s", auctionId), err)
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to void bids by auctionId %s", auctionId))
+	}
+
+	if _, err := bd.ProxyCollection.DeleteMany(ctx, bson.M{"auction_id": auctionId}); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to delete proxy bids by auctionId %s", auctionId), err)
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to delete proxy bids by auctionId %s", auctionId))
+	}
+
+	bd.forgetHighestBid(auctionId)
+
+	logger.Info("Auction bids voided",
+		zap.String("auction_id", auctionId),
+		zap.Int64("bids", result.ModifiedCount))
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+const (
+	appendOperation = "append"
+	commitOperation = "commit"
+)
+
+// walRecord é uma linha do arquivo: um lance enfileirado (append) ou a
+// confirmação de que um lote foi processado (commit)
+type walRecord struct {
+	Operation string          `json:"op"`
+	Bid       *bid_entity.Bid `json:"bid,omitempty"`
+	BidIds    []string        `json:"bid_ids,omitempty"`
+}
+
+// FileBidLog é um write-ahead log em arquivo local, uma entrada JSON por
+// linha. Cada escrita é sincronizada com o disco antes de retornar. O arquivo
+// é truncado quando não há mais lances pendentes e, quando passa de
+// BID_WAL_COMPACT_SIZE, reescrito apenas com os lances pendentes, para que
+// não cresça sem limite sob carga constante.
+type FileBidLog struct {
+	path         string
+	file         *os.File
+	size         int64
+	compactSize  int64
+	compacted    int64
+	pending      map[string]bid_entity.Bid
+	pendingOrder []string
+	mutex        *sync.Mutex
+}
+
+func NewFileBidLog(path string) (*FileBidLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Error("Error trying to open bid write-ahead log", err)
+		return nil, err
+	}
+
+	bidLog := &FileBidLog{
+		path:        path,
+		file:        file,
+		compactSize: getBidLogCompactSize(),
+		pending:     make(map[string]bid_entity.Bid),
+		mutex:       &sync.Mutex{},
+	}
+
+	if err := bidLog.load(); err != nil {
+		file.Close()
+		logger.Error("Error trying to read bid write-ahead log", err)
+		return nil, err
+	}
+
+	bidLog.maybeCompact()
+
+	return bidLog, nil
+}
+
+// load reconstrói os lances pendentes a partir do arquivo. Uma linha
+// incompleta (queda no meio da escrita) é descartada.
+func (fl *FileBidLog) load() error {
+	scanner := bufio.NewScanner(fl.file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var record walRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			logger.Info("Skipping invalid bid write-ahead log entry", zap.String("entry", scanner.Text()))
+			continue
+		}
+
+		switch record.Operation {
+		case appendOperation:
+			if record.Bid != nil {
+				fl.addPending(*record.Bid)
+			}
+		case commitOperation:
+			fl.removePending(record.BidIds)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return fl.terminatePartialEntry()
+}
+
+// terminatePartialEntry fecha com quebra de linha uma entrada incompleta no
+// final do arquivo, para que a próxima escrita não seja concatenada a ela
+func (fl *FileBidLog) terminatePartialEntry() error {
+	info, err := fl.file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	lastByte := make([]byte, 1)
+	if _, err := fl.file.ReadAt(lastByte, info.Size()-1); err != nil {
+		return err
+	}
+
+	fl.size = info.Size()
+	if lastByte[0] == '\n' {
+		return nil
+	}
+
+	_, err = fl.file.Write([]byte{'\n'})
+	fl.size++
+	return err
+}
+
+func (fl *FileBidLog) Append(bid bid_entity.Bid) *internal_error.InternalError {
+	fl.mutex.Lock()
+	defer fl.mutex.Unlock()
+
+	if err := fl.write(walRecord{Operation: appendOperation, Bid: &bid}); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to append bid %s to write-ahead log", bid.Id), err)
+		return internal_error.NewInternalServerError("Error trying to persist bid")
+	}
+
+	fl.addPending(bid)
+	return nil
+}
+
+func (fl *FileBidLog) Commit(bidIds []string) *internal_error.InternalError {
+	if len(bidIds) == 0 {
+		return nil
+	}
+
+	fl.mutex.Lock()
+	defer fl.mutex.Unlock()
+
+	fl.removePending(bidIds)
+
+	if len(fl.pending) == 0 {
+		if err := fl.file.Truncate(0); err != nil {
+			logger.Error("Error trying to truncate bid write-ahead log", err)
+			return internal_error.NewInternalServerError("Error trying to commit bids")
+		}
+		fl.size, fl.compacted = 0, 0
+		return nil
+	}
+
+	if err := fl.write(walRecord{Operation: commitOperation, BidIds: bidIds}); err != nil {
+		logger.Error("Error trying to commit bids to write-ahead log", err)
+		return internal_error.NewInternalServerError("Error trying to commit bids")
+	}
+
+	fl.maybeCompact()
+
+	return nil
+}
+
+// maybeCompact compacta o arquivo quando ele passa do limite. Se os próprios
+// pendentes ocupam boa parte do limite, espera o arquivo dobrar em relação à
+// última compactação, para não reescrevê-lo a cada confirmação. Falhas são
+// apenas registradas: o arquivo atual continua válido.
+func (fl *FileBidLog) maybeCompact() {
+	if fl.compactSize <= 0 || fl.size < fl.compactSize || fl.size < 2*fl.compacted {
+		return
+	}
+
+	previousSize := fl.size
+	if err := fl.compact(); err != nil {
+		logger.Error("Error trying to compact bid write-ahead log", err)
+		return
+	}
+
+	logger.Info("Bid write-ahead log compacted",
+		zap.Int64("previous_size", previousSize),
+		zap.Int64("size", fl.size),
+		zap.Int("pending_bids", len(fl.pending)))
+}
+
+// compact reescreve em um arquivo temporário apenas os appends dos lances
+// pendentes, na ordem de chegada, e o renomeia sobre o log. O arquivo novo é
+// sincronizado antes do rename, então uma queda no meio deixa o log antigo ou
+// o novo, ambos completos.
+func (fl *FileBidLog) compact() error {
+	tempPath := fl.path + ".compact"
+	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, bidId := range fl.pendingOrder {
+		bid, ok := fl.pending[bidId]
+		if !ok {
+			continue
+		}
+
+		line, err := json.Marshal(walRecord{Operation: appendOperation, Bid: &bid})
+		if err == nil {
+			_, err = file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			file.Close()
+			os.Remove(tempPath)
+			return err
+		}
+		size += int64(len(line)) + 1
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := os.Rename(tempPath, fl.path); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	syncDir(filepath.Dir(fl.path))
+
+	fl.file.Close()
+	fl.file = file
+	fl.size, fl.compacted = size, size
+
+	return nil
+}
+
+// syncDir grava no disco a entrada do diretório alterada pelo rename
+func syncDir(path string) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer dir.Close()
+
+	dir.Sync()
+}
+
+// Pending retorna, na ordem de chegada, os lances ainda não confirmados
+func (fl *FileBidLog) Pending() ([]bid_entity.Bid, *internal_error.InternalError) {
+	fl.mutex.Lock()
+	defer fl.mutex.Unlock()
+
+	bids := make([]bid_entity.Bid, 0, len(fl.pending))
+	for _, bidId := range fl.pendingOrder {
+		if bid, ok := fl.pending[bidId]; ok {
+			bids = append(bids, bid)
+		}
+	}
+
+	return bids, nil
+}
+
+func (fl *FileBidLog) Close() error {
+	fl.mutex.Lock()
+	defer fl.mutex.Unlock()
+
+	return fl.file.Close()
+}
+
+func (fl *FileBidLog) write(record walRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fl.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	fl.size += int64(len(line)) + 1
+
+	return fl.file.Sync()
+}
+
+func (fl *FileBidLog) addPending(bid bid_entity.Bid) {
+	if _, exists := fl.pending[bid.Id]; !exists {
+		fl.pendingOrder = append(fl.pendingOrder, bid.Id)
+	}
+	fl.pending[bid.Id] = bid
+}
+
+func (fl *FileBidLog) removePending(bidIds []string) {
+	for _, bidId := range bidIds {
+		delete(fl.pending, bidId)
+	}
+
+	if len(fl.pendingOrder) <= 2*len(fl.pending) {
+		return
+	}
+
+	pendingOrder := make([]string, 0, len(fl.pending))
+	for _, bidId := range fl.pendingOrder {
+		if _, ok := fl.pending[bidId]; ok {
+			pendingOrder = append(pendingOrder, bidId)
+		}
+	}
+	fl.pendingOrder = pendingOrder
+}
+
+// getBidLogCompactSize obtém o tamanho, em bytes, a partir do qual o log é
+// compactado (padrão 4 MiB; zero desativa)
+func getBidLogCompactSize() int64 {
+	value, err := strconv.ParseInt(os.Getenv("BID_WAL_COMPACT_SIZE"), 10, 64)
+	if err != nil || value < 0 {
+		return 4 * 1024 * 1024
+	}
+
+	return value
+}
@@ -0,0 +1,137 @@
+package wal
+
+import (
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newQueuedBid(t *testing.T, amount float64) bid_entity.Bid {
+	bid, err := bid_entity.CreateBid(uuid.New().String(), uuid.New().String(), amount)
+	if err != nil {
+		t.Fatalf("Erro ao criar lance: %v", err)
+	}
+	return *bid
+}
+
+// Teste de recuperação: lances não confirmados voltam na ordem de chegada
+// depois de reabrir o arquivo
+func TestFileBidLogReplaysPendingBids(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.wal")
+
+	bidLog, err := NewFileBidLog(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir o log: %v", err)
+	}
+
+	first, second, third := newQueuedBid(t, 10), newQueuedBid(t, 20), newQueuedBid(t, 30)
+	for _, bid := range []bid_entity.Bid{first, second, third} {
+		if err := bidLog.Append(bid); err != nil {
+			t.Fatalf("Erro ao registrar lance: %v", err)
+		}
+	}
+	if err := bidLog.Commit([]string{second.Id}); err != nil {
+		t.Fatalf("Erro ao confirmar lance: %v", err)
+	}
+	bidLog.Close()
+
+	reopened, err := NewFileBidLog(path)
+	if err != nil {
+		t.Fatalf("Erro ao reabrir o log: %v", err)
+	}
+	defer reopened.Close()
+
+	pending, _ := reopened.Pending()
+	if len(pending) != 2 || pending[0].Id != first.Id || pending[1].Id != third.Id {
+		t.Fatalf("Lances pendentes esperados: %s e %s, obtidos: %+v", first.Id, third.Id, pending)
+	}
+	if pending[0].Amount != first.Amount || pending[0].AuctionId != first.AuctionId {
+		t.Errorf("Lance recuperado diferente do registrado: %+v", pending[0])
+	}
+}
+
+// Teste da compactação: o arquivo é truncado quando tudo foi confirmado e
+// uma linha incompleta no final é ignorada
+func TestFileBidLogTruncatesAndSkipsPartialEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.wal")
+
+	bidLog, err := NewFileBidLog(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir o log: %v", err)
+	}
+
+	bid := newQueuedBid(t, 10)
+	bidLog.Append(bid)
+	bidLog.Commit([]string{bid.Id})
+
+	info, _ := os.Stat(path)
+	if info.Size() != 0 {
+		t.Errorf("Log deveria estar vazio após confirmar todos os lances, tamanho: %d", info.Size())
+	}
+
+	pendingBid := newQueuedBid(t, 20)
+	bidLog.Append(pendingBid)
+	bidLog.Close()
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(`{"op":"append","bid":{"Id":"`)
+	file.Close()
+
+	reopened, err := NewFileBidLog(path)
+	if err != nil {
+		t.Fatalf("Erro ao reabrir o log: %v", err)
+	}
+	defer reopened.Close()
+
+	pending, _ := reopened.Pending()
+	if len(pending) != 1 || pending[0].Id != pendingBid.Id {
+		t.Errorf("Apenas o lance completo deveria estar pendente, obtidos: %+v", pending)
+	}
+}
+
+// Teste da compactação sob carga: com lances sempre pendentes o arquivo é
+// reescrito só com eles ao passar do limite, sem perder nenhum
+func TestFileBidLogCompactsWhileBidsArePending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.wal")
+
+	bidLog, err := NewFileBidLog(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir o log: %v", err)
+	}
+	bidLog.compactSize = 4 * 1024
+
+	// Um lance fica pendente enquanto os demais são confirmados um a um
+	pendingBid := newQueuedBid(t, 10)
+	bidLog.Append(pendingBid)
+	var lastBid bid_entity.Bid
+	for i := 0; i < 200; i++ {
+		lastBid = newQueuedBid(t, float64(20+i))
+		bidLog.Append(lastBid)
+		if i < 199 {
+			bidLog.Commit([]string{lastBid.Id})
+		}
+	}
+
+	info, _ := os.Stat(path)
+	if info.Size() >= 2*bidLog.compactSize {
+		t.Errorf("Log deveria ter sido compactado, tamanho: %d", info.Size())
+	}
+	bidLog.Close()
+
+	reopened, err := NewFileBidLog(path)
+	if err != nil {
+		t.Fatalf("Erro ao reabrir o log: %v", err)
+	}
+	defer reopened.Close()
+
+	pending, _ := reopened.Pending()
+	if len(pending) != 2 || pending[0].Id != pendingBid.Id || pending[1].Id != lastBid.Id {
+		t.Errorf("Lances pendentes esperados: %s e %s, obtidos: %+v", pendingBid.Id, lastBid.Id, pending)
+	}
+	if _, err := os.Stat(path + ".compact"); !os.IsNotExist(err) {
+		t.Errorf("Arquivo temporário da compactação não deveria sobrar, erro: %v", err)
+	}
+}
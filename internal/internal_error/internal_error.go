@@ -3,6 +3,12 @@ package internal_error
 type InternalError struct {
 	Message string
 	Err     string
+	Causes  []Causes
+}
+
+type Causes struct {
+	Field   string
+	Message string
 }
 
 func (ie *InternalError) Error() string {
@@ -23,9 +29,17 @@ func NewInternalServerError(message string) *InternalError {
 	}
 }
 
-func NewBadRequestError(message string) *InternalError {
+func NewBadRequestError(message string, causes ...Causes) *InternalError {
 	return &InternalError{
 		Message: message,
 		Err:     "bad_request",
+		Causes:  causes,
+	}
+}
+
+func NewServiceUnavailableError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "service_unavailable",
 	}
 }
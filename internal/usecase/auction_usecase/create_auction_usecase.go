@@ -10,25 +10,84 @@ import (
 )
 
 type AuctionInputDTO struct {
-	ProductName string           `json:"product_name" binding:"required,min=1"`
-	Category    string           `json:"category" binding:"required,min=2"`
-	Description string           `json:"description" binding:"required,min=10,max=200"`
-	Condition   ProductCondition `json:"condition" binding:"oneof=0 1 2"`
+	Draft                bool               `json:"draft"`
+	ProductName          string             `json:"product_name" binding:"required,min=1"`
+	Category             string             `json:"category" binding:"required_unless=Draft true,omitempty,min=2"`
+	Description          string             `json:"description" binding:"required_unless=Draft true,omitempty,min=10,max=200"`
+	Condition            ProductCondition   `json:"condition" binding:"oneof=0 1 2"`
+	AuctionType          AuctionType        `json:"auction_type" binding:"oneof=0 1 2 3 4"`
+	StartsAt             *time.Time         `json:"starts_at"`
+	EndsAt               *time.Time         `json:"ends_at"`
+	Duration             string             `json:"duration"`
+	ReservePrice         float64            `json:"reserve_price" binding:"gte=0"`
+	StartingPrice        float64            `json:"starting_price" binding:"gte=0"`
+	MinIncrement         float64            `json:"min_increment" binding:"gte=0"`
+	IncrementTiers       []IncrementTierDTO `json:"increment_tiers" binding:"dive"`
+	PriceDecrement       float64            `json:"price_decrement" binding:"gte=0"`
+	PriceDropInterval    string             `json:"price_drop_interval"`
+	Quantity             int                `json:"quantity" binding:"gte=0"`
+	PricingRule          PricingRule        `json:"pricing_rule" binding:"oneof=0 1"`
+	BuyNowPrice          float64            `json:"buy_now_price" binding:"gte=0"`
+	AutoRelist           int                `json:"auto_relist" binding:"gte=0"`
+	RelistPriceReduction float64            `json:"relist_price_reduction" binding:"gte=0,lt=100"`
+}
+
+type IncrementTierDTO struct {
+	From      float64 `json:"from" binding:"gte=0"`
+	Increment float64 `json:"increment" binding:"gt=0"`
 }
 
 type AuctionOutputDTO struct {
-	Id          string           `json:"id"`
-	ProductName string           `json:"product_name"`
-	Category    string           `json:"category"`
-	Description string           `json:"description"`
-	Condition   ProductCondition `json:"condition"`
-	Status      AuctionStatus    `json:"status"`
-	Timestamp   time.Time        `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Id                   string             `json:"id"`
+	ProductName          string             `json:"product_name"`
+	Category             string             `json:"category"`
+	Description          string             `json:"description"`
+	Condition            ProductCondition   `json:"condition"`
+	AuctionType          AuctionType        `json:"auction_type"`
+	Status               AuctionStatus      `json:"status"`
+	Timestamp            time.Time          `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	StartsAt             time.Time          `json:"starts_at" time_format:"2006-01-02 15:04:05"`
+	EndsAt               time.Time          `json:"ends_at" time_format:"2006-01-02 15:04:05"`
+	StartingPrice        float64            `json:"starting_price"`
+	MinIncrement         float64            `json:"min_increment"`
+	IncrementTiers       []IncrementTierDTO `json:"increment_tiers,omitempty"`
+	PriceDecrement       float64            `json:"price_decrement,omitempty"`
+	PriceDropInterval    string             `json:"price_drop_interval,omitempty"`
+	CurrentPrice         float64            `json:"current_price,omitempty"`
+	Quantity             int                `json:"quantity"`
+	PricingRule          PricingRule        `json:"pricing_rule"`
+	BuyNowPrice          float64            `json:"buy_now_price,omitempty"`
+	RelistedFromId       string             `json:"relisted_from_id,omitempty"`
+	PausedAt             *time.Time         `json:"paused_at,omitempty"`
+	AutoRelist           int                `json:"auto_relist,omitempty"`
+	RelistPriceReduction float64            `json:"relist_price_reduction,omitempty"`
+	RelistCount          int                `json:"relist_count,omitempty"`
+	OriginalAuctionId    string             `json:"original_auction_id,omitempty"`
+	Version              int                `json:"version"`
+	Clarifications       []ClarificationDTO `json:"clarifications,omitempty"`
+	Duration             string             `json:"duration,omitempty"`
 }
 
+// WinningInfoOutputDTO traz o lance vencedor e o preço que o vencedor paga
+// (clearing_price), que só difere do lance no leilão de segundo preço e no
+// de várias unidades com preço uniforme. Nos leilões de várias unidades
+// allocations lista todos os vencedores e, no pay-as-bid, clearing_price fica
+// ausente.
 type WinningInfoOutputDTO struct {
-	Auction AuctionOutputDTO          `json:"auction"`
-	Bid     *bid_usecase.BidOutputDTO `json:"bid,omitempty"`
+	Auction       AuctionOutputDTO          `json:"auction"`
+	Bid           *bid_usecase.BidOutputDTO `json:"bid,omitempty"`
+	ClearingPrice float64                   `json:"clearing_price,omitempty"`
+	Allocations   []AllocationOutputDTO     `json:"allocations,omitempty"`
+	ReserveMet    bool                      `json:"reserve_met"`
+}
+
+// AllocationOutputDTO é a parte da oferta de um leilão de várias unidades
+// atribuída a um lance vencedor e o preço unitário que ele paga
+type AllocationOutputDTO struct {
+	BidId     string  `json:"bid_id"`
+	UserId    string  `json:"user_id"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
 }
 
 func NewAuctionUseCase(
@@ -43,7 +102,7 @@ func NewAuctionUseCase(
 type AuctionUseCaseInterface interface {
 	CreateAuction(
 		ctx context.Context,
-		auctionInput AuctionInputDTO) *internal_error.InternalError
+		auctionInput AuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
 
 	FindAuctionById(
 		ctx context.Context, id string) (*AuctionOutputDTO, *internal_error.InternalError)
@@ -56,32 +115,280 @@ type AuctionUseCaseInterface interface {
 	FindWinningBidByAuctionId(
 		ctx context.Context,
 		auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError)
+
+	SettleAuction(
+		ctx context.Context, auctionId string) *internal_error.InternalError
+
+	AcceptPrice(
+		ctx context.Context,
+		auctionId string,
+		purchaseInput PurchaseInputDTO) (*bid_usecase.BidOutputDTO, *internal_error.InternalError)
+
+	BuyNow(
+		ctx context.Context,
+		auctionId string,
+		purchaseInput PurchaseInputDTO) (*bid_usecase.BidOutputDTO, *internal_error.InternalError)
+
+	EndAuction(
+		ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	CancelAuction(
+		ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	PauseAuction(
+		ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	ResumeAuction(
+		ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	RelistAuction(
+		ctx context.Context,
+		auctionId string,
+		relistInput RelistAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	UpdateAuction(
+		ctx context.Context,
+		auctionId string,
+		updateInput AuctionUpdateInputDTO,
+		replace bool) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	FindAuctionRevisions(
+		ctx context.Context, auctionId string) ([]AuctionRevisionOutputDTO, *internal_error.InternalError)
+
+	PublishAuction(
+		ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError)
 }
 
 type ProductCondition int64
+type AuctionType int64
 type AuctionStatus int64
+type PricingRule int64
 
 type AuctionUseCase struct {
 	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface
 	bidRepositoryInterface     bid_entity.BidEntityRepository
 }
 
+// CreateAuction cria o leilão ou, com draft, um rascunho que só é validado
+// na publicação
 func (au *AuctionUseCase) CreateAuction(
 	ctx context.Context,
-	auctionInput AuctionInputDTO) *internal_error.InternalError {
-	auction, err := auction_entity.CreateAuction(
-		auctionInput.ProductName,
-		auctionInput.Category,
-		auctionInput.Description,
-		auction_entity.ProductCondition(auctionInput.Condition))
+	auctionInput AuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	options, err := auctionInput.auctionOptions()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var auction *auction_entity.Auction
+	if auctionInput.Draft {
+		auction = auction_entity.CreateDraftAuction(
+			auctionInput.ProductName,
+			auctionInput.Category,
+			auctionInput.Description,
+			auction_entity.ProductCondition(auctionInput.Condition),
+			options...)
+	} else {
+		auction, err = auction_entity.CreateAuction(
+			auctionInput.ProductName,
+			auctionInput.Category,
+			auctionInput.Description,
+			auction_entity.ProductCondition(auctionInput.Condition),
+			options...)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if err := au.auctionRepositoryInterface.CreateAuction(
 		ctx, auction); err != nil {
-		return err
+		return nil, err
+	}
+
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+
+	return &auctionOutputDTO, nil
+}
+
+// auctionOptions converte os campos opcionais da entrada nas opções do
+// leilão. A duração é contada a partir do início (nos rascunhos, a partir
+// da publicação) e não pode ser combinada com ends_at.
+func (input AuctionInputDTO) auctionOptions() ([]auction_entity.AuctionOption, *internal_error.InternalError) {
+	var incrementTiers []auction_entity.IncrementTier
+	for _, tier := range input.IncrementTiers {
+		incrementTiers = append(incrementTiers, auction_entity.IncrementTier{
+			From:      tier.From,
+			Increment: tier.Increment,
+		})
+	}
+
+	options := []auction_entity.AuctionOption{
+		auction_entity.WithAuctionType(auction_entity.AuctionType(input.AuctionType)),
+		auction_entity.WithReservePrice(input.ReservePrice),
+		auction_entity.WithStartingPrice(input.StartingPrice),
+		auction_entity.WithMinIncrement(input.MinIncrement),
+		auction_entity.WithIncrementTiers(incrementTiers),
+		auction_entity.WithPricingRule(auction_entity.PricingRule(input.PricingRule)),
+		auction_entity.WithBuyNowPrice(input.BuyNowPrice),
+		auction_entity.WithAutoRelist(input.AutoRelist, input.RelistPriceReduction),
 	}
 
-	return nil
+	if input.Quantity > 0 {
+		options = append(options, auction_entity.WithQuantity(input.Quantity))
+	}
+
+	if input.Draft {
+		schedule, err := draftScheduleOptions(input.StartsAt, input.EndsAt, input.Duration)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, schedule...)
+	} else {
+		schedule, err := scheduleOptions(input.StartsAt, input.EndsAt, input.Duration)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, schedule...)
+	}
+
+	if input.PriceDropInterval != "" {
+		priceDropInterval, err := time.ParseDuration(input.PriceDropInterval)
+		if err != nil || priceDropInterval <= 0 {
+			return nil, internal_error.NewBadRequestError("price_drop_interval is not a valid value")
+		}
+
+		options = append(options, auction_entity.WithPriceDrop(input.PriceDecrement, priceDropInterval))
+	}
+
+	return options, nil
+}
+
+// scheduleOptions converte o início, o término e a duração informados nas
+// opções de horário do leilão. Com duração o início é sempre fixado, para
+// que ela seja contada a partir do mesmo instante.
+func scheduleOptions(
+	startsAt, endsAt *time.Time,
+	duration string) ([]auction_entity.AuctionOption, *internal_error.InternalError) {
+	var options []auction_entity.AuctionOption
+
+	start := time.Now()
+	if startsAt != nil {
+		start = *startsAt
+	}
+	if startsAt != nil || duration != "" {
+		options = append(options, auction_entity.WithStartsAt(start))
+	}
+
+	if endsAt != nil && duration != "" {
+		return nil, internal_error.NewBadRequestError("ends_at and duration cannot be informed together")
+	}
+
+	if endsAt != nil {
+		options = append(options, auction_entity.WithEndsAt(*endsAt))
+	}
+
+	if duration != "" {
+		parsedDuration, err := time.ParseDuration(duration)
+		if err != nil || parsedDuration <= 0 {
+			return nil, internal_error.NewBadRequestError("duration is not a valid value")
+		}
+
+		options = append(options, auction_entity.WithEndsAt(start.Add(parsedDuration)))
+	}
+
+	return options, nil
+}
+
+// draftScheduleOptions converte os horários de um rascunho. O início não é
+// fixado e a duração é guardada, para ser contada na publicação.
+func draftScheduleOptions(
+	startsAt, endsAt *time.Time,
+	duration string) ([]auction_entity.AuctionOption, *internal_error.InternalError) {
+	var options []auction_entity.AuctionOption
+
+	if endsAt != nil && duration != "" {
+		return nil, internal_error.NewBadRequestError("ends_at and duration cannot be informed together")
+	}
+
+	if startsAt != nil {
+		options = append(options, auction_entity.WithStartsAt(*startsAt))
+	}
+
+	if endsAt != nil {
+		options = append(options, auction_entity.WithEndsAt(*endsAt))
+	}
+
+	if duration != "" {
+		parsedDuration, err := time.ParseDuration(duration)
+		if err != nil || parsedDuration <= 0 {
+			return nil, internal_error.NewBadRequestError("duration is not a valid value")
+		}
+
+		options = append(options, auction_entity.WithDuration(parsedDuration))
+	}
+
+	return options, nil
+}
+
+func newAuctionOutputDTO(auction *auction_entity.Auction) AuctionOutputDTO {
+	var incrementTiers []IncrementTierDTO
+	for _, tier := range auction.IncrementTiers {
+		incrementTiers = append(incrementTiers, IncrementTierDTO{
+			From:      tier.From,
+			Increment: tier.Increment,
+		})
+	}
+
+	var priceDropInterval string
+	if auction.Type == auction_entity.Dutch {
+		priceDropInterval = auction.PriceDropInterval.String()
+	}
+
+	var clarifications []ClarificationDTO
+	for _, clarification := range auction.Clarifications {
+		clarifications = append(clarifications, ClarificationDTO{
+			Text:      clarification.Text,
+			CreatedAt: clarification.CreatedAt,
+		})
+	}
+
+	var duration string
+	if auction.Status == auction_entity.Draft && auction.DraftDuration > 0 {
+		duration = auction.DraftDuration.String()
+	}
+
+	var pausedAt *time.Time
+	if auction.Status == auction_entity.Paused {
+		pausedAt = &auction.PausedAt
+	}
+
+	return AuctionOutputDTO{
+		Id:                   auction.Id,
+		ProductName:          auction.ProductName,
+		Category:             auction.Category,
+		Description:          auction.Description,
+		Condition:            ProductCondition(auction.Condition),
+		AuctionType:          AuctionType(auction.Type),
+		Status:               AuctionStatus(auction.Status),
+		Timestamp:            auction.Timestamp,
+		StartsAt:             auction.StartsAt,
+		EndsAt:               auction.EndsAt,
+		StartingPrice:        auction.StartingPrice,
+		MinIncrement:         auction.MinIncrement,
+		IncrementTiers:       incrementTiers,
+		PriceDecrement:       auction.PriceDecrement,
+		PriceDropInterval:    priceDropInterval,
+		CurrentPrice:         auction.CurrentPrice,
+		Quantity:             auction.Quantity,
+		PricingRule:          PricingRule(auction.PricingRule),
+		BuyNowPrice:          auction.BuyNowPrice,
+		RelistedFromId:       auction.RelistedFromId,
+		PausedAt:             pausedAt,
+		AutoRelist:           auction.MaxAutoRelists,
+		RelistPriceReduction: auction.RelistPriceReduction,
+		RelistCount:          auction.RelistCount,
+		OriginalAuctionId:    auction.OriginalAuctionId,
+		Version:              auction.Version,
+		Clarifications:       clarifications,
+		Duration:             duration,
+	}
 }
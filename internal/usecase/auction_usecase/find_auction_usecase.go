@@ -2,8 +2,8 @@ package auction_usecase
 
 import (
 	"context"
-	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
 )
@@ -15,15 +15,9 @@ func (au *AuctionUseCase) FindAuctionById(
 		return nil, err
 	}
 
-	return &AuctionOutputDTO{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   ProductCondition(auctionEntity.Condition),
-		Status:      AuctionStatus(auctionEntity.Status),
-		Timestamp:   auctionEntity.Timestamp,
-	}, nil
+	auctionOutputDTO := newAuctionOutputDTO(auctionEntity)
+
+	return &auctionOutputDTO, nil
 }
 
 func (au *AuctionUseCase) FindAuctions(
@@ -38,15 +32,7 @@ func (au *AuctionUseCase) FindAuctions(
 
 	var auctionOutputs []AuctionOutputDTO
 	for _, value := range auctionEntities {
-		auctionOutputs = append(auctionOutputs, AuctionOutputDTO{
-			Id:          value.Id,
-			ProductName: value.ProductName,
-			Category:    value.Category,
-			Description: value.Description,
-			Condition:   ProductCondition(value.Condition),
-			Status:      AuctionStatus(value.Status),
-			Timestamp:   value.Timestamp,
-		})
+		auctionOutputs = append(auctionOutputs, newAuctionOutputDTO(&value))
 	}
 
 	return auctionOutputs, nil
@@ -60,22 +46,36 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		return nil, err
 	}
 
-	auctionOutputDTO := AuctionOutputDTO{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   ProductCondition(auction.Condition),
-		Status:      AuctionStatus(auction.Status),
-		Timestamp:   auction.Timestamp,
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+
+	if !auction.BidsAreVisible() {
+		return &WinningInfoOutputDTO{
+			Auction:    auctionOutputDTO,
+			Bid:        nil,
+			ReserveMet: false,
+		}, nil
 	}
 
+	// Leilão sem lances não é um erro: o resultado vem sem vencedor (e, se
+	// configurado, o leilão é relistado na apuração)
 	bidWinning, err := au.bidRepositoryInterface.FindWinningBidByAuctionId(ctx, auction.Id)
 	if err != nil {
-		logger.Error("", err)
+		if err.Err != "not_found" {
+			return nil, err
+		}
+
 		return &WinningInfoOutputDTO{
-			Auction: auctionOutputDTO,
-			Bid:     nil,
+			Auction:    auctionOutputDTO,
+			Bid:        nil,
+			ReserveMet: auction.ReservePrice <= 0,
+		}, nil
+	}
+
+	if !auction.IsReserveMet(bidWinning.Amount) {
+		return &WinningInfoOutputDTO{
+			Auction:    auctionOutputDTO,
+			Bid:        nil,
+			ReserveMet: false,
 		}, nil
 	}
 
@@ -84,11 +84,94 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		UserId:    bidWinning.UserId,
 		AuctionId: bidWinning.AuctionId,
 		Amount:    bidWinning.Amount,
+		Quantity:  bidWinning.Quantity,
 		Timestamp: bidWinning.Timestamp,
 	}
 
+	clearingPrice, err := au.findClearingPrice(ctx, auction, bidWinning)
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []AllocationOutputDTO
+	if auction.IsMultiUnit() {
+		topBids, err := au.bidRepositoryInterface.FindTopBidsByAuctionId(ctx, auction.Id, int64(auction.Quantity))
+		if err != nil {
+			return nil, err
+		}
+
+		// Só no preço uniforme há um preço único pago por todos os vencedores
+		allocations = allocateUnits(auction, topBids)
+		clearingPrice = 0
+		if auction.PricingRule == auction_entity.UniformPrice && len(allocations) > 0 {
+			clearingPrice = allocations[0].UnitPrice
+		}
+	}
+
 	return &WinningInfoOutputDTO{
-		Auction: auctionOutputDTO,
-		Bid:     bidOutputDTO,
+		Auction:       auctionOutputDTO,
+		Bid:           bidOutputDTO,
+		ClearingPrice: clearingPrice,
+		Allocations:   allocations,
+		ReserveMet:    true,
 	}, nil
 }
+
+// allocateUnits distribui as unidades entre os lances, já ordenados do
+// melhor para o pior, até acabar a oferta. O último vencedor pode levar só
+// parte do que pediu e lances abaixo da reserva não vencem. No preço uniforme
+// todos pagam o menor lance vencedor; no pay-as-bid cada um paga o próprio.
+func allocateUnits(auction *auction_entity.Auction, bids []bid_entity.Bid) []AllocationOutputDTO {
+	var allocations []AllocationOutputDTO
+	remaining := auction.Quantity
+	for _, bid := range bids {
+		if remaining == 0 || !auction.IsReserveMet(bid.Amount) {
+			break
+		}
+
+		quantity := bid.Quantity
+		if quantity > remaining {
+			quantity = remaining
+		}
+		remaining -= quantity
+
+		allocations = append(allocations, AllocationOutputDTO{
+			BidId:     bid.Id,
+			UserId:    bid.UserId,
+			Quantity:  quantity,
+			UnitPrice: bid.Amount,
+		})
+	}
+
+	if auction.PricingRule == auction_entity.UniformPrice && len(allocations) > 0 {
+		clearingPrice := allocations[len(allocations)-1].UnitPrice
+		for i := range allocations {
+			allocations[i].UnitPrice = clearingPrice
+		}
+	}
+
+	return allocations
+}
+
+// findClearingPrice busca o segundo maior lance quando o leilão é de
+// segundo preço; nos demais o vencedor paga o próprio lance
+func (au *AuctionUseCase) findClearingPrice(
+	ctx context.Context,
+	auction *auction_entity.Auction,
+	bidWinning *bid_entity.Bid) (float64, *internal_error.InternalError) {
+	if auction.Type != auction_entity.SealedSecondPrice {
+		return auction.ClearingPrice(bidWinning.Amount, 0), nil
+	}
+
+	topBids, err := au.bidRepositoryInterface.FindTopBidsByAuctionId(ctx, auction.Id, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	runnerUpAmount := 0.0
+	if len(topBids) > 1 {
+		runnerUpAmount = topBids[1].Amount
+	}
+
+	return auction.ClearingPrice(bidWinning.Amount, runnerUpAmount), nil
+}
@@ -0,0 +1,167 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"testing"
+	"time"
+)
+
+// Teste do vencedor de leilão fechado: oculto enquanto o leilão está ativo e
+// revelado, pelo próprio valor, após o encerramento
+func TestFindWinningBidSealedAuction(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	bidRepo := NewMockBidRepository()
+	useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+	auction, err := auction_entity.CreateAuction(
+		"Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithAuctionType(auction_entity.SealedFirstPrice))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+	auctionRepo.CreateAuction(context.Background(), auction)
+
+	for i, amount := range []float64{300, 100, 200} {
+		bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+			Id:        string(rune('a' + i)),
+			AuctionId: auction.Id,
+			Amount:    amount,
+			Timestamp: time.Now(),
+		}})
+	}
+
+	winningInfo, _ := useCase.FindWinningBidByAuctionId(context.Background(), auction.Id)
+	if winningInfo.Bid != nil {
+		t.Errorf("Vencedor de leilão fechado ativo não deveria ser exposto: %+v", winningInfo.Bid)
+	}
+
+	auctionRepo.UpdateAuctionStatus(context.Background(), auction.Id, auction_entity.Completed)
+
+	winningInfo, _ = useCase.FindWinningBidByAuctionId(context.Background(), auction.Id)
+	if winningInfo.Bid == nil || winningInfo.Bid.Amount != 300 || winningInfo.ClearingPrice != 300 {
+		t.Errorf("Vencedor deveria ser o lance de 300 pagando o próprio valor, obtido: %+v", winningInfo)
+	}
+}
+
+// Teste do leilão de segundo preço: o vencedor paga o segundo maior lance,
+// com piso na reserva
+func TestFindWinningBidVickreyAuction(t *testing.T) {
+	testCases := []struct {
+		reservePrice  float64
+		bidAmounts    []float64
+		clearingPrice float64
+		description   string
+	}{
+		{0, []float64{300, 100, 200}, 200, "paga o segundo maior lance"},
+		{250, []float64{300}, 250, "lance único paga a reserva"},
+		{150, []float64{300, 100}, 150, "segundo lance abaixo da reserva"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auctionRepo := NewMockAuctionRepository()
+			bidRepo := NewMockBidRepository()
+			useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+			auction, err := auction_entity.CreateAuction(
+				"Test Product", "Electronics", "Test Description", auction_entity.New,
+				auction_entity.WithAuctionType(auction_entity.SealedSecondPrice),
+				auction_entity.WithReservePrice(tc.reservePrice))
+			if err != nil {
+				t.Fatalf("Erro ao criar leilão: %v", err)
+			}
+			auction.Status = auction_entity.Completed
+			auctionRepo.CreateAuction(context.Background(), auction)
+
+			for i, amount := range tc.bidAmounts {
+				bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+					Id:        string(rune('a' + i)),
+					AuctionId: auction.Id,
+					Amount:    amount,
+					Timestamp: time.Now(),
+				}})
+			}
+
+			winningInfo, err := useCase.FindWinningBidByAuctionId(context.Background(), auction.Id)
+			if err != nil {
+				t.Fatalf("Erro ao buscar vencedor: %v", err)
+			}
+			if winningInfo.Bid == nil || winningInfo.Bid.Amount != tc.bidAmounts[0] {
+				t.Fatalf("Vencedor deveria ser o lance de %.2f, obtido: %+v", tc.bidAmounts[0], winningInfo.Bid)
+			}
+			if winningInfo.ClearingPrice != tc.clearingPrice {
+				t.Errorf("clearing_price esperado: %.2f, obtido: %.2f", tc.clearingPrice, winningInfo.ClearingPrice)
+			}
+		})
+	}
+}
+
+// Teste do leilão de várias unidades: os maiores lances levam as unidades até
+// acabar a oferta, o último parcialmente, com preço próprio ou uniforme
+func TestFindWinningBidMultiUnitAuction(t *testing.T) {
+	testCases := []struct {
+		pricingRule   auction_entity.PricingRule
+		unitPrices    []float64
+		clearingPrice float64
+		description   string
+	}{
+		{auction_entity.PayAsBid, []float64{300, 200, 150}, 0, "cada um paga o próprio lance"},
+		{auction_entity.UniformPrice, []float64{150, 150, 150}, 150, "todos pagam o menor lance vencedor"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auctionRepo := NewMockAuctionRepository()
+			bidRepo := NewMockBidRepository()
+			useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+			auction, err := auction_entity.CreateAuction(
+				"Test Product", "Electronics", "Test Description", auction_entity.New,
+				auction_entity.WithQuantity(5),
+				auction_entity.WithPricingRule(tc.pricingRule),
+				auction_entity.WithReservePrice(120))
+			if err != nil {
+				t.Fatalf("Erro ao criar leilão: %v", err)
+			}
+			auction.Status = auction_entity.Completed
+			auctionRepo.CreateAuction(context.Background(), auction)
+
+			bids := []struct {
+				amount   float64
+				quantity int
+			}{{300, 2}, {150, 3}, {200, 2}, {100, 1}}
+			for i, bid := range bids {
+				bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+					Id:        string(rune('a' + i)),
+					AuctionId: auction.Id,
+					Amount:    bid.amount,
+					Quantity:  bid.quantity,
+					Timestamp: time.Now(),
+				}})
+			}
+
+			winningInfo, err := useCase.FindWinningBidByAuctionId(context.Background(), auction.Id)
+			if err != nil {
+				t.Fatalf("Erro ao buscar vencedores: %v", err)
+			}
+
+			expectedQuantities := []int{2, 2, 1}
+			if len(winningInfo.Allocations) != len(expectedQuantities) {
+				t.Fatalf("Alocações esperadas: %d, obtidas: %+v", len(expectedQuantities), winningInfo.Allocations)
+			}
+			for i, allocation := range winningInfo.Allocations {
+				if allocation.Quantity != expectedQuantities[i] || allocation.UnitPrice != tc.unitPrices[i] {
+					t.Errorf("Alocação %d esperada: %d por %.2f, obtida: %+v",
+						i, expectedQuantities[i], tc.unitPrices[i], allocation)
+				}
+			}
+
+			// No pay-as-bid não há preço único, então clearing_price fica ausente
+			if winningInfo.ClearingPrice != tc.clearingPrice {
+				t.Errorf("clearing_price esperado: %.2f, obtido: %.2f", tc.clearingPrice, winningInfo.ClearingPrice)
+			}
+		})
+	}
+}
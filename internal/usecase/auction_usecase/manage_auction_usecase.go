@@ -0,0 +1,200 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// RelistAuctionInputDTO traz o horário do novo leilão; sem ele o leilão
+// começa agora e dura o mesmo que o original
+type RelistAuctionInputDTO struct {
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Duration string     `json:"duration"`
+}
+
+// EndAuction encerra um leilão ativo antes do horário. A apuração (reserva e
+// vencedor) é a mesma do encerramento pelo agendador.
+func (au *AuctionUseCase) EndAuction(
+	ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	return au.changeAuctionStatus(ctx, auctionId, auction_entity.Completed)
+}
+
+// CancelAuction cancela um leilão agendado ou ativo e anula todos os lances,
+// que deixam de contar para vencedor e reserva. Cancelar de novo um leilão já
+// cancelado repete a anulação, para concluir uma que tenha falhado.
+func (au *AuctionUseCase) CancelAuction(
+	ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var auctionOutput *AuctionOutputDTO
+	if auction.Status == auction_entity.Cancelled {
+		auctionOutputDTO := newAuctionOutputDTO(auction)
+		auctionOutput = &auctionOutputDTO
+	} else {
+		auctionOutput, err = au.changeAuctionStatus(ctx, auctionId, auction_entity.Cancelled)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := au.bidRepositoryInterface.VoidBidsByAuctionId(ctx, auctionId); err != nil {
+		return nil, err
+	}
+
+	return auctionOutput, nil
+}
+
+// PauseAuction congela um leilão ativo: lances são recusados e o término
+// deixa de correr até a retomada
+func (au *AuctionUseCase) PauseAuction(
+	ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auction.Pause(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.PauseAuction(ctx, auction); err != nil {
+		return nil, err
+	}
+
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+
+	return &auctionOutputDTO, nil
+}
+
+// ResumeAuction reabre um leilão pausado com o término adiado pelo tempo que
+// ele ficou em pausa
+func (au *AuctionUseCase) ResumeAuction(
+	ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	pausedAt := auction.PausedAt
+	if err := auction.Resume(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.ResumeAuction(ctx, auction, pausedAt); err != nil {
+		return nil, err
+	}
+
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+
+	return &auctionOutputDTO, nil
+}
+
+// RelistAuction cria um novo leilão com as regras de um leilão encerrado sem
+// venda ou cancelado. Leilões vendidos não podem ser relistados.
+func (au *AuctionUseCase) RelistAuction(
+	ctx context.Context,
+	auctionId string,
+	relistInput RelistAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	options, err := scheduleOptions(relistInput.StartsAt, relistInput.EndsAt, relistInput.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction.Status == auction_entity.Completed {
+		sold, err := au.isSold(ctx, auction)
+		if err != nil {
+			return nil, err
+		}
+
+		if sold {
+			return nil, internal_error.NewBadRequestError("Sold auctions cannot be relisted")
+		}
+	}
+
+	relisted, err := auction.Relist(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.CreateAuction(ctx, relisted); err != nil {
+		return nil, err
+	}
+
+	auctionOutputDTO := newAuctionOutputDTO(relisted)
+
+	return &auctionOutputDTO, nil
+}
+
+// PublishAuction valida o rascunho e o coloca no ar; o relógio do leilão
+// (início, término e reduções de preço) só começa a contar agora
+func (au *AuctionUseCase) PublishAuction(
+	ctx context.Context, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auction.Publish(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.PublishAuction(ctx, auction); err != nil {
+		return nil, err
+	}
+
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+
+	return &auctionOutputDTO, nil
+}
+
+// changeAuctionStatus valida a mudança de status contra as transições do
+// leilão e a grava condicionada ao status lido
+func (au *AuctionUseCase) changeAuctionStatus(
+	ctx context.Context,
+	auctionId string,
+	status auction_entity.AuctionStatus) (*AuctionOutputDTO, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auction.ValidateTransition(status); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.ChangeAuctionStatus(
+		ctx, auctionId, auction.Status, status); err != nil {
+		return nil, err
+	}
+
+	auction.Status = status
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+
+	return &auctionOutputDTO, nil
+}
+
+// isSold indica se o leilão encerrado tem um lance vencedor que atinge a
+// reserva
+func (au *AuctionUseCase) isSold(
+	ctx context.Context, auction *auction_entity.Auction) (bool, *internal_error.InternalError) {
+	bidWinning, err := au.bidRepositoryInterface.FindWinningBidByAuctionId(ctx, auction.Id)
+	if err != nil {
+		if err.Err == "not_found" {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return auction.IsReserveMet(bidWinning.Amount), nil
+}
@@ -0,0 +1,233 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"testing"
+	"time"
+)
+
+func createActiveAuction(t *testing.T, repo *MockAuctionRepository, options ...auction_entity.AuctionOption) *auction_entity.Auction {
+	options = append(options, auction_entity.WithEndsAt(time.Now().Add(time.Hour)))
+	auction, err := auction_entity.CreateAuction(
+		"Test Product", "Electronics", "Test Description", auction_entity.New, options...)
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	repo.CreateAuction(context.Background(), auction)
+
+	return auction
+}
+
+// Teste do encerramento antecipado e das transições recusadas
+func TestEndAuction(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	useCase := NewAuctionUseCase(auctionRepo, NewMockBidRepository())
+
+	auction := createActiveAuction(t, auctionRepo)
+
+	output, err := useCase.EndAuction(context.Background(), auction.Id)
+	if err != nil {
+		t.Fatalf("Erro ao encerrar leilão: %v", err)
+	}
+	if output.Status != AuctionStatus(auction_entity.Completed) {
+		t.Errorf("Status esperado: %v, obtido: %v", auction_entity.Completed, output.Status)
+	}
+
+	if _, err := useCase.EndAuction(context.Background(), auction.Id); err == nil {
+		t.Error("Leilão encerrado não deveria ser encerrado de novo")
+	}
+	if _, err := useCase.CancelAuction(context.Background(), auction.Id); err == nil {
+		t.Error("Leilão encerrado não deveria ser cancelado")
+	}
+}
+
+// Teste do cancelamento, que anula os lances do leilão
+func TestCancelAuction(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	bidRepo := NewMockBidRepository()
+	useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+	auction := createActiveAuction(t, auctionRepo)
+	bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+		Id:        "bid",
+		AuctionId: auction.Id,
+		Amount:    150,
+		Timestamp: time.Now(),
+	}})
+
+	output, err := useCase.CancelAuction(context.Background(), auction.Id)
+	if err != nil {
+		t.Fatalf("Erro ao cancelar leilão: %v", err)
+	}
+	if output.Status != AuctionStatus(auction_entity.Cancelled) {
+		t.Errorf("Status esperado: %v, obtido: %v", auction_entity.Cancelled, output.Status)
+	}
+
+	if _, err := bidRepo.FindWinningBidByAuctionId(context.Background(), auction.Id); err == nil {
+		t.Error("Lances do leilão cancelado deveriam ser anulados")
+	}
+}
+
+// Teste do cancelamento repetido, que conclui a anulação que falhou
+func TestCancelAuctionRetriesVoiding(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	bidRepo := NewMockBidRepository()
+	useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+	auction := createActiveAuction(t, auctionRepo)
+	bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+		Id:        "bid",
+		AuctionId: auction.Id,
+		Amount:    150,
+		Timestamp: time.Now(),
+	}})
+
+	bidRepo.voidErr = internal_error.NewInternalServerError("Error trying to void bids")
+	if _, err := useCase.CancelAuction(context.Background(), auction.Id); err == nil {
+		t.Fatal("Falha ao anular os lances deveria ser retornada")
+	}
+
+	bidRepo.voidErr = nil
+	output, err := useCase.CancelAuction(context.Background(), auction.Id)
+	if err != nil {
+		t.Fatalf("Cancelamento repetido deveria concluir a anulação: %v", err)
+	}
+	if output.Status != AuctionStatus(auction_entity.Cancelled) {
+		t.Errorf("Status esperado: %v, obtido: %v", auction_entity.Cancelled, output.Status)
+	}
+
+	if _, err := bidRepo.FindWinningBidByAuctionId(context.Background(), auction.Id); err == nil {
+		t.Error("Lances do leilão cancelado deveriam ser anulados")
+	}
+}
+
+// Teste da relistagem de leilões encerrados sem venda, cancelados e vendidos
+func TestRelistAuction(t *testing.T) {
+	testCases := []struct {
+		status      auction_entity.AuctionStatus
+		bidAmount   float64
+		shouldPass  bool
+		description string
+	}{
+		{auction_entity.EndedWithoutSale, 50, true, "encerrado sem venda"},
+		{auction_entity.Cancelled, 0, true, "cancelado"},
+		{auction_entity.Completed, 0, true, "encerrado sem lances"},
+		{auction_entity.Completed, 150, false, "vendido"},
+		{auction_entity.Active, 0, false, "ativo"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auctionRepo := NewMockAuctionRepository()
+			bidRepo := NewMockBidRepository()
+			useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+			auction := createActiveAuction(t, auctionRepo, auction_entity.WithReservePrice(100))
+			auction.Status = tc.status
+			if tc.bidAmount > 0 {
+				bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+					Id:        "bid",
+					AuctionId: auction.Id,
+					Amount:    tc.bidAmount,
+					Timestamp: time.Now(),
+				}})
+			}
+
+			output, err := useCase.RelistAuction(context.Background(), auction.Id, RelistAuctionInputDTO{Duration: "2h"})
+			if !tc.shouldPass {
+				if err == nil {
+					t.Error("Leilão não deveria ser relistado")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Erro ao relistar leilão: %v", err)
+			}
+			if output.RelistedFromId != auction.Id || output.Id == auction.Id {
+				t.Errorf("Leilão relistado deveria ter novo id ligado ao original: %+v", output)
+			}
+			if output.EndsAt.Sub(output.StartsAt) != 2*time.Hour {
+				t.Errorf("Leilão relistado deveria durar 2h, duração: %v", output.EndsAt.Sub(output.StartsAt))
+			}
+			if _, err := auctionRepo.FindAuctionById(context.Background(), output.Id); err != nil {
+				t.Errorf("Leilão relistado deveria ser gravado: %v", err)
+			}
+		})
+	}
+}
+
+// Teste da pausa e da retomada, que adia o término
+func TestPauseAndResumeAuction(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	useCase := NewAuctionUseCase(auctionRepo, NewMockBidRepository())
+
+	auction := createActiveAuction(t, auctionRepo)
+
+	if _, err := useCase.ResumeAuction(context.Background(), auction.Id); err == nil {
+		t.Error("Leilão ativo não deveria ser retomado")
+	}
+
+	output, err := useCase.PauseAuction(context.Background(), auction.Id)
+	if err != nil {
+		t.Fatalf("Erro ao pausar leilão: %v", err)
+	}
+	if output.Status != AuctionStatus(auction_entity.Paused) || output.PausedAt == nil {
+		t.Errorf("Leilão deveria estar pausado: %+v", output)
+	}
+
+	// Simula uma pausa de 10 minutos
+	auctionRepo.auctions[auction.Id].PausedAt = time.Now().Add(-10 * time.Minute)
+
+	output, err = useCase.ResumeAuction(context.Background(), auction.Id)
+	if err != nil {
+		t.Fatalf("Erro ao retomar leilão: %v", err)
+	}
+	if output.Status != AuctionStatus(auction_entity.Active) || output.PausedAt != nil {
+		t.Errorf("Leilão deveria estar ativo: %+v", output)
+	}
+	if shift := output.EndsAt.Sub(auction.EndsAt); shift < 10*time.Minute {
+		t.Errorf("Término deveria ser adiado pelo tempo em pausa, adiamento: %v", shift)
+	}
+}
+
+// Teste da criação de rascunho e da publicação
+func TestPublishAuction(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	useCase := NewAuctionUseCase(auctionRepo, NewMockBidRepository())
+
+	draft, err := useCase.CreateAuction(context.Background(), AuctionInputDTO{
+		Draft:       true,
+		ProductName: "Test Product",
+		Category:    "Electronics",
+		Duration:    "1h",
+	})
+	if err != nil {
+		t.Fatalf("Erro ao criar rascunho: %v", err)
+	}
+	if draft.Status != AuctionStatus(auction_entity.Draft) || draft.Duration != "1h0m0s" {
+		t.Errorf("Rascunho deveria guardar a duração: %+v", draft)
+	}
+
+	if _, err := useCase.PublishAuction(context.Background(), draft.Id); err == nil {
+		t.Error("Rascunho sem descrição não deveria ser publicado")
+	}
+
+	auctionRepo.auctions[draft.Id].Description = "Test Description"
+
+	output, err := useCase.PublishAuction(context.Background(), draft.Id)
+	if err != nil {
+		t.Fatalf("Erro ao publicar rascunho: %v", err)
+	}
+	if output.Status != AuctionStatus(auction_entity.Active) || output.EndsAt.Sub(output.StartsAt) != time.Hour {
+		t.Errorf("Leilão publicado deveria estar ativo por 1h: %+v", output)
+	}
+
+	if _, err := useCase.PublishAuction(context.Background(), draft.Id); err == nil {
+		t.Error("Leilão publicado não deveria ser publicado de novo")
+	}
+}
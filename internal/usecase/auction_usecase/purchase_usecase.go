@@ -0,0 +1,59 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+
+	"github.com/google/uuid"
+)
+
+// PurchaseInputDTO identifica quem encerra o leilão comprando: aceitando o
+// preço do leilão holandês ou pelo preço de compra imediata
+type PurchaseInputDTO struct {
+	UserId string `json:"user_id" binding:"required"`
+}
+
+// AcceptPrice encerra o leilão holandês com o usuário como vencedor pelo
+// preço atual, retornando o lance gravado com o valor pago
+func (au *AuctionUseCase) AcceptPrice(
+	ctx context.Context,
+	auctionId string,
+	purchaseInput PurchaseInputDTO) (*bid_usecase.BidOutputDTO, *internal_error.InternalError) {
+	return au.purchase(ctx, auctionId, purchaseInput, au.bidRepositoryInterface.AcceptAuctionPrice)
+}
+
+// BuyNow encerra o leilão com o usuário como vencedor pelo preço de compra
+// imediata, retornando o lance gravado
+func (au *AuctionUseCase) BuyNow(
+	ctx context.Context,
+	auctionId string,
+	purchaseInput PurchaseInputDTO) (*bid_usecase.BidOutputDTO, *internal_error.InternalError) {
+	return au.purchase(ctx, auctionId, purchaseInput, au.bidRepositoryInterface.BuyAuctionNow)
+}
+
+func (au *AuctionUseCase) purchase(
+	ctx context.Context,
+	auctionId string,
+	purchaseInput PurchaseInputDTO,
+	complete func(ctx context.Context, auctionId, userId string) (*bid_entity.Bid, *internal_error.InternalError),
+) (*bid_usecase.BidOutputDTO, *internal_error.InternalError) {
+	if err := uuid.Validate(purchaseInput.UserId); err != nil {
+		return nil, internal_error.NewBadRequestError("UserId is not a valid id")
+	}
+
+	bidEntity, err := complete(ctx, auctionId, purchaseInput.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bid_usecase.BidOutputDTO{
+		Id:        bidEntity.Id,
+		UserId:    bidEntity.UserId,
+		AuctionId: bidEntity.AuctionId,
+		Amount:    bidEntity.Amount,
+		Quantity:  bidEntity.Quantity,
+		Timestamp: bidEntity.Timestamp,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.uber.org/zap"
+)
+
+// SettleAuction apura o resultado de um leilão recém-fechado. Leilões que
+// terminam abaixo do preço de reserva são marcados como encerrados sem venda
+// e os que terminam sem lances são relistados automaticamente, se configurados.
+func (au *AuctionUseCase) SettleAuction(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	if auction.Status != auction_entity.Completed {
+		return nil
+	}
+
+	bidWinning, err := au.bidRepositoryInterface.FindWinningBidByAuctionId(ctx, auctionId)
+	if err != nil && err.Err != "not_found" {
+		return err
+	}
+
+	if bidWinning != nil && auction.IsReserveMet(bidWinning.Amount) {
+		return nil
+	}
+
+	if auction.ReservePrice > 0 {
+		if err := au.auctionRepositoryInterface.UpdateAuctionStatus(
+			ctx, auctionId, auction_entity.EndedWithoutSale); err != nil {
+			return err
+		}
+
+		logger.Info("Auction ended without sale", zap.String("auction_id", auctionId))
+	}
+
+	if bidWinning == nil && auction.CanAutoRelist() {
+		return au.autoRelistAuction(ctx, auction)
+	}
+
+	return nil
+}
+
+// autoRelistAuction cria a próxima relistagem automática do leilão
+func (au *AuctionUseCase) autoRelistAuction(
+	ctx context.Context, auction *auction_entity.Auction) *internal_error.InternalError {
+	relisted, err := auction.AutoRelist()
+	if err != nil {
+		return err
+	}
+
+	if err := au.auctionRepositoryInterface.CreateAuction(ctx, relisted); err != nil {
+		return err
+	}
+
+	logger.Info("Auction relisted automatically",
+		zap.String("auction_id", auction.Id),
+		zap.String("relisted_auction_id", relisted.Id),
+		zap.Int("relist_count", relisted.RelistCount))
+
+	return nil
+}
@@ -0,0 +1,380 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MockAuctionRepository para testes do caso de uso
+type MockAuctionRepository struct {
+	auctions  map[string]*auction_entity.Auction
+	revisions map[string][]auction_entity.AuctionRevision
+	mutex     sync.RWMutex
+}
+
+func NewMockAuctionRepository() *MockAuctionRepository {
+	return &MockAuctionRepository{
+		auctions:  make(map[string]*auction_entity.Auction),
+		revisions: make(map[string][]auction_entity.AuctionRevision),
+	}
+}
+
+func (m *MockAuctionRepository) CreateAuction(ctx context.Context, auction *auction_entity.Auction) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.auctions[auction.Id] = auction
+	return nil
+}
+
+func (m *MockAuctionRepository) FindAuctionById(ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	auction, exists := m.auctions[id]
+	if !exists {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+	auctionCopy := *auction
+	return &auctionCopy, nil
+}
+
+func (m *MockAuctionRepository) FindAuctions(ctx context.Context, status auction_entity.AuctionStatus, category, productName string) ([]auction_entity.Auction, *internal_error.InternalError) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var result []auction_entity.Auction
+	for _, auction := range m.auctions {
+		result = append(result, *auction)
+	}
+	return result, nil
+}
+
+func (m *MockAuctionRepository) UpdateAuctionStatus(ctx context.Context, auctionId string, status auction_entity.AuctionStatus) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	auction, exists := m.auctions[auctionId]
+	if !exists {
+		return internal_error.NewNotFoundError("Auction not found")
+	}
+	auction.Status = status
+	return nil
+}
+
+func (m *MockAuctionRepository) ChangeAuctionStatus(ctx context.Context, auctionId string, from, to auction_entity.AuctionStatus) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	auction, exists := m.auctions[auctionId]
+	if !exists {
+		return internal_error.NewNotFoundError("Auction not found")
+	}
+	if auction.Status != from {
+		return internal_error.NewBadRequestError("Auction status has changed, please try again")
+	}
+	auction.Status = to
+	return nil
+}
+
+func (m *MockAuctionRepository) PauseAuction(ctx context.Context, auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	return m.ChangeAuctionStatus(ctx, auctionEntity.Id, auction_entity.Active, auction_entity.Paused)
+}
+
+func (m *MockAuctionRepository) ResumeAuction(ctx context.Context, auctionEntity *auction_entity.Auction, pausedAt time.Time) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	auction, exists := m.auctions[auctionEntity.Id]
+	if !exists || auction.Status != auction_entity.Paused {
+		return internal_error.NewBadRequestError("Auction status has changed, please try again")
+	}
+	auctionCopy := *auctionEntity
+	m.auctions[auctionEntity.Id] = &auctionCopy
+	return nil
+}
+
+func (m *MockAuctionRepository) UpdateAuction(ctx context.Context, auctionEntity *auction_entity.Auction, previous *auction_entity.Auction) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	auction, exists := m.auctions[auctionEntity.Id]
+	if !exists || auction.Version != previous.Version {
+		return internal_error.NewBadRequestError("Auction was edited or closed concurrently, please try again")
+	}
+	m.revisions[previous.Id] = append(m.revisions[previous.Id], auction_entity.AuctionRevision{
+		AuctionId:  previous.Id,
+		Version:    previous.Version,
+		Auction:    *previous,
+		ReplacedAt: time.Now(),
+	})
+	auctionCopy := *auctionEntity
+	m.auctions[auctionEntity.Id] = &auctionCopy
+	return nil
+}
+
+func (m *MockAuctionRepository) FindAuctionRevisions(ctx context.Context, auctionId string) ([]auction_entity.AuctionRevision, *internal_error.InternalError) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.revisions[auctionId], nil
+}
+
+func (m *MockAuctionRepository) PublishAuction(ctx context.Context, auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	auction, exists := m.auctions[auctionEntity.Id]
+	if !exists || auction.Status != auction_entity.Draft {
+		return internal_error.NewBadRequestError("Auction status has changed, please try again")
+	}
+	auctionCopy := *auctionEntity
+	m.auctions[auctionEntity.Id] = &auctionCopy
+	return nil
+}
+
+// MockBidRepository para testes do caso de uso
+type MockBidRepository struct {
+	bids    map[string][]bid_entity.Bid
+	queued  map[string][]bid_entity.Bid
+	voidErr *internal_error.InternalError
+	mutex   sync.RWMutex
+}
+
+func NewMockBidRepository() *MockBidRepository {
+	return &MockBidRepository{
+		bids:   make(map[string][]bid_entity.Bid),
+		queued: make(map[string][]bid_entity.Bid),
+	}
+}
+
+func (m *MockBidRepository) ValidateBid(ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	return nil
+}
+
+func (m *MockBidRepository) CreateBid(ctx context.Context, bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var results []bid_entity.BidResult
+	for _, bid := range bidEntities {
+		m.bids[bid.AuctionId] = append(m.bids[bid.AuctionId], bid)
+		results = append(results, bid_entity.NewAcceptedBidResult(bid.Id))
+	}
+	return results, nil
+}
+
+func (m *MockBidRepository) FindBidById(ctx context.Context, bidId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("Bid not found")
+}
+
+func (m *MockBidRepository) QueueBid(ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.queued[bidEntity.AuctionId] = append(m.queued[bidEntity.AuctionId], bidEntity)
+	return nil
+}
+
+func (m *MockBidRepository) LockAuctionBids(ctx context.Context, auctionId string) (bool, func(), *internal_error.InternalError) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return len(m.bids[auctionId]) > 0 || len(m.queued[auctionId]) > 0, func() {}, nil
+}
+
+func (m *MockBidRepository) DiscardQueuedBid(ctx context.Context, bidId string) *internal_error.InternalError {
+	return nil
+}
+
+func (m *MockBidRepository) CreateProxyBid(ctx context.Context, proxyBid bid_entity.ProxyBid) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewInternalServerError("Proxy bids are not supported by the mock")
+}
+
+func (m *MockBidRepository) AcceptAuctionPrice(ctx context.Context, auctionId, userId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewBadRequestError("Only dutch auctions accept the current price")
+}
+
+func (m *MockBidRepository) BuyAuctionNow(ctx context.Context, auctionId, userId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewBadRequestError("Auction has no buy-it-now price")
+}
+
+func (m *MockBidRepository) VoidBidsByAuctionId(ctx context.Context, auctionId string) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.voidErr != nil {
+		return m.voidErr
+	}
+	delete(m.bids, auctionId)
+	return nil
+}
+
+func (m *MockBidRepository) FindBidByAuctionId(ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.bids[auctionId], nil
+}
+
+func (m *MockBidRepository) FindWinningBidByAuctionId(ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	bids := append([]bid_entity.Bid{}, m.bids[auctionId]...)
+	if len(bids) == 0 {
+		return nil, internal_error.NewNotFoundError("No bids found")
+	}
+
+	sort.Slice(bids, func(i, j int) bool { return bids[i].Amount > bids[j].Amount })
+	return &bids[0], nil
+}
+
+func (m *MockBidRepository) FindTopBidsByAuctionId(ctx context.Context, auctionId string, limit int64) ([]bid_entity.Bid, *internal_error.InternalError) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	bids := append([]bid_entity.Bid{}, m.bids[auctionId]...)
+	sort.Slice(bids, func(i, j int) bool { return bids[i].Amount > bids[j].Amount })
+	if int64(len(bids)) > limit {
+		bids = bids[:limit]
+	}
+	return bids, nil
+}
+
+func createCompletedAuction(t *testing.T, repo *MockAuctionRepository, reservePrice float64) *auction_entity.Auction {
+	auction, err := auction_entity.CreateAuction(
+		"Test Product", "Electronics", "Test Description", auction_entity.New,
+		auction_entity.WithReservePrice(reservePrice))
+	if err != nil {
+		t.Fatalf("Erro ao criar leilão: %v", err)
+	}
+
+	auction.Status = auction_entity.Completed
+	repo.CreateAuction(context.Background(), auction)
+
+	return auction
+}
+
+// Teste da apuração de leilões com preço de reserva
+func TestSettleAuctionReservePrice(t *testing.T) {
+	testCases := []struct {
+		reservePrice float64
+		bidAmounts   []float64
+		expected     auction_entity.AuctionStatus
+		description  string
+	}{
+		{0, nil, auction_entity.Completed, "sem reserva e sem lances"},
+		{100, []float64{50, 150}, auction_entity.Completed, "reserva atingida"},
+		{100, []float64{100}, auction_entity.Completed, "lance igual à reserva"},
+		{100, []float64{50, 99.99}, auction_entity.EndedWithoutSale, "reserva não atingida"},
+		{100, nil, auction_entity.EndedWithoutSale, "reserva sem lances"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auctionRepo := NewMockAuctionRepository()
+			bidRepo := NewMockBidRepository()
+			useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+			auction := createCompletedAuction(t, auctionRepo, tc.reservePrice)
+			for _, amount := range tc.bidAmounts {
+				bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+					Id:        "bid",
+					AuctionId: auction.Id,
+					Amount:    amount,
+					Timestamp: time.Now(),
+				}})
+			}
+
+			if err := useCase.SettleAuction(context.Background(), auction.Id); err != nil {
+				t.Fatalf("Erro ao apurar leilão: %v", err)
+			}
+
+			foundAuction, _ := auctionRepo.FindAuctionById(context.Background(), auction.Id)
+			if foundAuction.Status != tc.expected {
+				t.Errorf("Status esperado: %v, obtido: %v", tc.expected, foundAuction.Status)
+			}
+
+			winningInfo, err := useCase.FindWinningBidByAuctionId(context.Background(), auction.Id)
+			if err != nil {
+				t.Fatalf("Erro ao buscar vencedor: %v", err)
+			}
+
+			reserveMet := tc.expected == auction_entity.Completed
+			if winningInfo.ReserveMet != reserveMet {
+				t.Errorf("reserve_met esperado: %v, obtido: %v", reserveMet, winningInfo.ReserveMet)
+			}
+			if !reserveMet && winningInfo.Bid != nil {
+				t.Errorf("Leilão sem venda não deveria ter vencedor: %+v", winningInfo.Bid)
+			}
+		})
+	}
+}
+
+// Teste da relistagem automática de leilões encerrados sem lances
+func TestSettleAuctionAutoRelist(t *testing.T) {
+	testCases := []struct {
+		bidAmounts  []float64
+		relistCount int
+		relisted    bool
+		description string
+	}{
+		{nil, 0, true, "sem lances"},
+		{[]float64{50}, 0, false, "lance abaixo da reserva"},
+		{nil, 1, false, "limite atingido"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			auctionRepo := NewMockAuctionRepository()
+			bidRepo := NewMockBidRepository()
+			useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+			auction, err := auction_entity.CreateAuction(
+				"Test Product", "Electronics", "Test Description", auction_entity.New,
+				auction_entity.WithEndsAt(time.Now().Add(time.Hour)),
+				auction_entity.WithStartingPrice(100),
+				auction_entity.WithReservePrice(200),
+				auction_entity.WithAutoRelist(1, 25))
+			if err != nil {
+				t.Fatalf("Erro ao criar leilão: %v", err)
+			}
+			auction.Status = auction_entity.Completed
+			auction.RelistCount = tc.relistCount
+			auctionRepo.CreateAuction(context.Background(), auction)
+
+			for _, amount := range tc.bidAmounts {
+				bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+					Id:        "bid",
+					AuctionId: auction.Id,
+					Amount:    amount,
+					Timestamp: time.Now(),
+				}})
+			}
+
+			if err := useCase.SettleAuction(context.Background(), auction.Id); err != nil {
+				t.Fatalf("Erro ao apurar leilão: %v", err)
+			}
+
+			auctions, _ := auctionRepo.FindAuctions(context.Background(), auction_entity.Active, "", "")
+			var relisted *auction_entity.Auction
+			for i := range auctions {
+				if auctions[i].RelistedFromId == auction.Id {
+					relisted = &auctions[i]
+				}
+			}
+
+			if (relisted != nil) != tc.relisted {
+				t.Fatalf("Relistagem esperada: %v, obtida: %v", tc.relisted, relisted != nil)
+			}
+			if relisted != nil && (relisted.StartingPrice != 75 || relisted.ReservePrice != 150) {
+				t.Errorf("Preços deveriam cair 25%%: %.2f, %.2f", relisted.StartingPrice, relisted.ReservePrice)
+			}
+
+			foundAuction, _ := auctionRepo.FindAuctionById(context.Background(), auction.Id)
+			if foundAuction.Status != auction_entity.EndedWithoutSale {
+				t.Errorf("Leilão original deveria terminar sem venda, status: %v", foundAuction.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// AuctionUpdateInputDTO traz a edição de um leilão. No PATCH os campos
+// omitidos mantêm o valor atual; no PUT os campos de preço omitidos voltam ao
+// padrão e descrição e categoria são obrigatórias. Depois do primeiro lance
+// só clarification é aceito.
+type AuctionUpdateInputDTO struct {
+	Description    *string             `json:"description" binding:"omitempty,min=10,max=200"`
+	Category       *string             `json:"category" binding:"omitempty,min=2"`
+	StartingPrice  *float64            `json:"starting_price" binding:"omitempty,gte=0"`
+	ReservePrice   *float64            `json:"reserve_price" binding:"omitempty,gte=0"`
+	MinIncrement   *float64            `json:"min_increment" binding:"omitempty,gte=0"`
+	IncrementTiers *[]IncrementTierDTO `json:"increment_tiers" binding:"omitempty,dive"`
+	PricingRule    *PricingRule        `json:"pricing_rule" binding:"omitempty,oneof=0 1"`
+	BuyNowPrice    *float64            `json:"buy_now_price" binding:"omitempty,gte=0"`
+	Clarification  string              `json:"clarification" binding:"max=200"`
+}
+
+type ClarificationDTO struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at" time_format:"2006-01-02 15:04:05"`
+}
+
+// AuctionRevisionOutputDTO é uma versão anterior do leilão e quando ela foi
+// substituída
+type AuctionRevisionOutputDTO struct {
+	Version    int              `json:"version"`
+	ReplacedAt time.Time        `json:"replaced_at" time_format:"2006-01-02 15:04:05"`
+	Auction    AuctionOutputDTO `json:"auction"`
+}
+
+// UpdateAuction edita o leilão (PATCH) ou substitui os campos editáveis (PUT,
+// com replace). Enquanto não há lances descrição, categoria e regras de preço
+// podem mudar; depois só é possível acrescentar esclarecimentos. Os lances do
+// leilão ficam travados da verificação até a gravação, então um lance
+// enfileirado ou aceito nesse meio tempo não é avaliado com regras diferentes
+// das que valiam quando foi confirmado.
+func (au *AuctionUseCase) UpdateAuction(
+	ctx context.Context,
+	auctionId string,
+	updateInput AuctionUpdateInputDTO,
+	replace bool) (*AuctionOutputDTO, *internal_error.InternalError) {
+	hasBids, unlock, err := au.bidRepositoryInterface.LockAuctionBids(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	edit, err := updateInput.auctionEdit(replace && !(hasBids && updateInput.isClarificationOnly()))
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *auction
+	if err := auction.Edit(edit, hasBids, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.UpdateAuction(ctx, auction, &previous); err != nil {
+		return nil, err
+	}
+
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+
+	return &auctionOutputDTO, nil
+}
+
+func (au *AuctionUseCase) FindAuctionRevisions(
+	ctx context.Context, auctionId string) ([]AuctionRevisionOutputDTO, *internal_error.InternalError) {
+	if _, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId); err != nil {
+		return nil, err
+	}
+
+	revisions, err := au.auctionRepositoryInterface.FindAuctionRevisions(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	revisionOutputs := make([]AuctionRevisionOutputDTO, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionOutputs = append(revisionOutputs, AuctionRevisionOutputDTO{
+			Version:    revision.Version,
+			ReplacedAt: revision.ReplacedAt,
+			Auction:    newAuctionOutputDTO(&revision.Auction),
+		})
+	}
+
+	return revisionOutputs, nil
+}
+
+func (input AuctionUpdateInputDTO) isClarificationOnly() bool {
+	return input.Description == nil && input.Category == nil && input.StartingPrice == nil &&
+		input.ReservePrice == nil && input.MinIncrement == nil && input.IncrementTiers == nil &&
+		input.PricingRule == nil && input.BuyNowPrice == nil
+}
+
+// auctionEdit converte a entrada na edição do leilão. Com replace os campos
+// de preço omitidos são zerados e descrição e categoria são obrigatórias.
+func (input AuctionUpdateInputDTO) auctionEdit(replace bool) (auction_entity.AuctionEdit, *internal_error.InternalError) {
+	if replace {
+		if input.Description == nil || input.Category == nil {
+			return auction_entity.AuctionEdit{}, internal_error.NewBadRequestError(
+				"description and category are required to replace an auction")
+		}
+
+		input.StartingPrice = zeroIfNil(input.StartingPrice)
+		input.ReservePrice = zeroIfNil(input.ReservePrice)
+		input.MinIncrement = zeroIfNil(input.MinIncrement)
+		input.BuyNowPrice = zeroIfNil(input.BuyNowPrice)
+		if input.IncrementTiers == nil {
+			input.IncrementTiers = &[]IncrementTierDTO{}
+		}
+		if input.PricingRule == nil {
+			payAsBid := PricingRule(auction_entity.PayAsBid)
+			input.PricingRule = &payAsBid
+		}
+	}
+
+	edit := auction_entity.AuctionEdit{
+		Description:   input.Description,
+		Category:      input.Category,
+		StartingPrice: input.StartingPrice,
+		ReservePrice:  input.ReservePrice,
+		MinIncrement:  input.MinIncrement,
+		BuyNowPrice:   input.BuyNowPrice,
+		Clarification: input.Clarification,
+	}
+
+	if input.IncrementTiers != nil {
+		incrementTiers := make([]auction_entity.IncrementTier, 0, len(*input.IncrementTiers))
+		for _, tier := range *input.IncrementTiers {
+			incrementTiers = append(incrementTiers, auction_entity.IncrementTier{
+				From:      tier.From,
+				Increment: tier.Increment,
+			})
+		}
+		edit.IncrementTiers = &incrementTiers
+	}
+
+	if input.PricingRule != nil {
+		pricingRule := auction_entity.PricingRule(*input.PricingRule)
+		edit.PricingRule = &pricingRule
+	}
+
+	return edit, nil
+}
+
+func zeroIfNil(value *float64) *float64 {
+	if value != nil {
+		return value
+	}
+
+	zero := 0.0
+	return &zero
+}
@@ -0,0 +1,104 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"testing"
+	"time"
+)
+
+// Teste da edição antes e depois do primeiro lance e das revisões guardadas
+func TestUpdateAuction(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	bidRepo := NewMockBidRepository()
+	useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+	auction := createActiveAuction(t, auctionRepo)
+
+	category := "Computers"
+	startingPrice := 50.0
+	output, err := useCase.UpdateAuction(context.Background(), auction.Id, AuctionUpdateInputDTO{
+		Category:      &category,
+		StartingPrice: &startingPrice,
+	}, false)
+	if err != nil {
+		t.Fatalf("Erro ao editar leilão: %v", err)
+	}
+	if output.Category != category || output.StartingPrice != 50 || output.Version != 2 {
+		t.Errorf("Edição não aplicada: %+v", output)
+	}
+
+	// PUT sem descrição é recusado; com ela, os preços omitidos voltam ao padrão
+	if _, err := useCase.UpdateAuction(context.Background(), auction.Id, AuctionUpdateInputDTO{
+		Category: &category,
+	}, true); err == nil {
+		t.Error("PUT sem descrição deveria ser recusado")
+	}
+
+	description := "Replaced description"
+	output, err = useCase.UpdateAuction(context.Background(), auction.Id, AuctionUpdateInputDTO{
+		Description: &description,
+		Category:    &category,
+	}, true)
+	if err != nil {
+		t.Fatalf("Erro ao substituir leilão: %v", err)
+	}
+	if output.Description != description || output.StartingPrice != 0 || output.Version != 3 {
+		t.Errorf("PUT deveria substituir os campos editáveis: %+v", output)
+	}
+
+	bidRepo.CreateBid(context.Background(), []bid_entity.Bid{{
+		Id:        "bid",
+		AuctionId: auction.Id,
+		Amount:    100,
+		Timestamp: time.Now(),
+	}})
+
+	if _, err := useCase.UpdateAuction(context.Background(), auction.Id, AuctionUpdateInputDTO{
+		StartingPrice: &startingPrice,
+	}, false); err == nil {
+		t.Error("Preço não deveria mudar depois do primeiro lance")
+	}
+
+	output, err = useCase.UpdateAuction(context.Background(), auction.Id, AuctionUpdateInputDTO{
+		Clarification: "Battery replaced last month",
+	}, true)
+	if err != nil {
+		t.Fatalf("Erro ao acrescentar esclarecimento: %v", err)
+	}
+	if len(output.Clarifications) != 1 || output.Description != description || output.Version != 4 {
+		t.Errorf("Esclarecimento deveria ser acrescentado: %+v", output)
+	}
+
+	revisions, err := useCase.FindAuctionRevisions(context.Background(), auction.Id)
+	if err != nil {
+		t.Fatalf("Erro ao buscar revisões: %v", err)
+	}
+	if len(revisions) != 3 || revisions[0].Version != 1 || revisions[0].Auction.Category != "Electronics" {
+		t.Errorf("Revisões deveriam guardar as versões anteriores: %+v", revisions)
+	}
+}
+
+// Teste da edição com lance na fila: o lance ainda não processado já trava os
+// campos de preço
+func TestUpdateAuctionWithQueuedBid(t *testing.T) {
+	auctionRepo := NewMockAuctionRepository()
+	bidRepo := NewMockBidRepository()
+	useCase := NewAuctionUseCase(auctionRepo, bidRepo)
+
+	auction := createActiveAuction(t, auctionRepo)
+
+	bidRepo.QueueBid(context.Background(), bid_entity.Bid{
+		Id:        "queued",
+		AuctionId: auction.Id,
+		Amount:    100,
+		Timestamp: time.Now(),
+	})
+
+	startingPrice := 50.0
+	if _, err := useCase.UpdateAuction(context.Background(), auction.Id, AuctionUpdateInputDTO{
+		StartingPrice: &startingPrice,
+	}, false); err == nil {
+		t.Error("Preço não deveria mudar com um lance na fila")
+	}
+}
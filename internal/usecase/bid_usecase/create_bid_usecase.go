@@ -7,13 +7,18 @@ import (
 	"fullcycle-auction_go/internal/internal_error"
 	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 type BidInputDTO struct {
 	UserId    string  `json:"user_id"`
 	AuctionId string  `json:"auction_id"`
 	Amount    float64 `json:"amount"`
+	Quantity  int     `json:"quantity"`
 }
 
 type BidOutputDTO struct {
@@ -21,98 +26,469 @@ type BidOutputDTO struct {
 	UserId    string    `json:"user_id"`
 	AuctionId string    `json:"auction_id"`
 	Amount    float64   `json:"amount"`
+	Quantity  int       `json:"quantity"`
 	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
 }
 
+type BidResultOutputDTO struct {
+	Id      string    `json:"id"`
+	Status  BidStatus `json:"status"`
+	Reason  string    `json:"reason,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+type BidStatus int64
+
+// BidQueueStatsOutputDTO expõe a ocupação da fila de lances do modo em lote
+type BidQueueStatsOutputDTO struct {
+	Mode             string `json:"mode"`
+	Capacity         int    `json:"capacity"`
+	Depth            int    `json:"depth"`
+	PendingBids      int    `json:"pending_bids"`
+	EnqueuedTotal    int64  `json:"enqueued_total"`
+	ShedTotal        int64  `json:"shed_total"`
+	EnqueueTimeoutMs int64  `json:"enqueue_timeout_ms"`
+}
+
 type BidUseCase struct {
 	BidRepository bid_entity.BidEntityRepository
+	BidLog        bid_entity.BidWriteAheadLog
 
+	syncMode            bool
+	pendingBids         map[string]struct{}
+	pendingBidsMutex    *sync.Mutex
 	timer               *time.Timer
 	maxBatchSize        int
 	batchInsertInterval time.Duration
 	bidChannel          chan bid_entity.Bid
+	flushChannel        chan flushRequest
+	enqueueTimeout      time.Duration
+	enqueuedTotal       *atomic.Int64
+	shedTotal           *atomic.Int64
+	closed              bool
+	closeMutex          *sync.RWMutex
+	routineDone         chan struct{}
 }
 
-func NewBidUseCase(bidRepository bid_entity.BidEntityRepository) BidUseCaseInterface {
+// NewBidUseCase cria o caso de uso de lances. bidLog é opcional (nil
+// desativa); quando informado, os lances pendentes de uma execução anterior
+// são reprocessados antes de aceitar novos lances.
+func NewBidUseCase(
+	bidRepository bid_entity.BidEntityRepository,
+	bidLog bid_entity.BidWriteAheadLog) BidUseCaseInterface {
 	maxSizeInterval := getMaxBatchSizeInterval()
 	maxBatchSize := getMaxBatchSize()
 
 	bidUseCase := &BidUseCase{
 		BidRepository:       bidRepository,
+		BidLog:              bidLog,
+		syncMode:            getBidProcessingMode() == syncProcessingMode,
+		pendingBids:         make(map[string]struct{}),
+		pendingBidsMutex:    &sync.Mutex{},
 		maxBatchSize:        maxBatchSize,
 		batchInsertInterval: maxSizeInterval,
 		timer:               time.NewTimer(maxSizeInterval),
-		bidChannel:          make(chan bid_entity.Bid, maxBatchSize),
+		bidChannel:          make(chan bid_entity.Bid, getBidQueueCapacity()),
+		flushChannel:        make(chan flushRequest),
+		enqueueTimeout:      getBidEnqueueTimeout(),
+		enqueuedTotal:       &atomic.Int64{},
+		shedTotal:           &atomic.Int64{},
+		closeMutex:          &sync.RWMutex{},
+		routineDone:         make(chan struct{}),
+	}
+
+	if bidLog != nil {
+		bidUseCase.replayBidLog(context.Background())
 	}
 
-	bidUseCase.triggerCreateRoutine(context.Background())
+	if !bidUseCase.syncMode {
+		bidUseCase.triggerCreateRoutine(context.Background())
+	}
 
 	return bidUseCase
 }
 
-var bidBatch []bid_entity.Bid
-
 type BidUseCaseInterface interface {
 	CreateBid(
 		ctx context.Context,
-		bidInputDTO BidInputDTO) *internal_error.InternalError
+		bidInputDTO BidInputDTO) (*BidResultOutputDTO, *internal_error.InternalError)
+
+	CreateProxyBid(
+		ctx context.Context,
+		proxyBidInputDTO ProxyBidInputDTO) (*ProxyBidOutputDTO, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context, auctionId string) (*BidOutputDTO, *internal_error.InternalError)
 
 	FindBidByAuctionId(
 		ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError)
+
+	FindBidStatus(
+		ctx context.Context, bidId string) (*BidResultOutputDTO, *internal_error.InternalError)
+
+	FindBidQueueStats(ctx context.Context) *BidQueueStatsOutputDTO
+
+	FlushAuction(ctx context.Context, auctionId string) *internal_error.InternalError
+
+	Close(ctx context.Context) *internal_error.InternalError
+}
+
+// flushRequest pede à goroutine de lotes que insira na hora o lote de um
+// leilão, avisando em done quando terminar
+type flushRequest struct {
+	auctionId string
+	done      chan struct{}
 }
 
+// triggerCreateRoutine consome a fila de lances mantendo um lote por leilão.
+// Um lote é inserido quando atinge MAX_BATCH_SIZE, quando o leilão vai ser
+// fechado (FlushAuction) e, a cada BATCH_INSERT_INTERVAL, todos os lotes
+// pendentes são inseridos. Os lotes pertencem à goroutine, por isso não há
+// estado compartilhado entre instâncias.
 func (bu *BidUseCase) triggerCreateRoutine(ctx context.Context) {
 	go func() {
-		defer close(bu.bidChannel)
+		defer close(bu.routineDone)
+
+		batches := make(map[string][]bid_entity.Bid)
+		var auctionOrder []string
+
+		addBid := func(bidEntity bid_entity.Bid) {
+			auctionId := bidEntity.AuctionId
+			if _, exists := batches[auctionId]; !exists {
+				auctionOrder = append(auctionOrder, auctionId)
+			}
+			batches[auctionId] = append(batches[auctionId], bidEntity)
+
+			if len(batches[auctionId]) >= bu.maxBatchSize {
+				bu.processBatch(ctx, batches[auctionId])
+
+				delete(batches, auctionId)
+				auctionOrder = removeAuctionId(auctionOrder, auctionId)
+			}
+		}
+
+		flushAll := func() {
+			var bidBatch []bid_entity.Bid
+			for _, auctionId := range auctionOrder {
+				bidBatch = append(bidBatch, batches[auctionId]...)
+			}
+
+			if len(bidBatch) > 0 {
+				bu.processBatch(ctx, bidBatch)
+			}
+
+			batches = make(map[string][]bid_entity.Bid)
+			auctionOrder = nil
+		}
 
 		for {
 			select {
 			case bidEntity, ok := <-bu.bidChannel:
 				if !ok {
-					if len(bidBatch) > 0 {
-						if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-							logger.Error("error trying to process bid batch list", err)
-						}
-					}
+					flushAll()
+					bu.timer.Stop()
 					return
 				}
 
-				bidBatch = append(bidBatch, bidEntity)
+				addBid(bidEntity)
+			case request := <-bu.flushChannel:
+				// Os lances que já estão no canal foram feitos antes do pedido
+				if !bu.drainBidChannel(addBid) {
+					flushAll()
+					bu.timer.Stop()
+					close(request.done)
+					return
+				}
 
-				if len(bidBatch) >= bu.maxBatchSize {
-					if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-						logger.Error("error trying to process bid batch list", err)
-					}
+				if len(batches[request.auctionId]) > 0 {
+					bu.processBatch(ctx, batches[request.auctionId])
 
-					bidBatch = nil
-					bu.timer.Reset(bu.batchInsertInterval)
+					delete(batches, request.auctionId)
+					auctionOrder = removeAuctionId(auctionOrder, request.auctionId)
 				}
+				close(request.done)
 			case <-bu.timer.C:
-				if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-					logger.Error("error trying to process bid batch list", err)
-				}
-				bidBatch = nil
+				flushAll()
 				bu.timer.Reset(bu.batchInsertInterval)
 			}
 		}
 	}()
 }
 
+// drainBidChannel passa para os lotes os lances que já estão no canal, sem
+// esperar por novos. Retorna false se o canal foi fechado.
+func (bu *BidUseCase) drainBidChannel(addBid func(bid_entity.Bid)) bool {
+	for {
+		select {
+		case bidEntity, ok := <-bu.bidChannel:
+			if !ok {
+				return false
+			}
+			addBid(bidEntity)
+		default:
+			return true
+		}
+	}
+}
+
+// FlushAuction insere na hora os lances enfileirados do leilão, inclusive os
+// que ainda estão no canal. O agendador chama antes de fechar o leilão, para
+// que os lances feitos antes do término sejam avaliados com o leilão ativo
+// independentemente do BATCH_INSERT_INTERVAL. Só cobre a fila desta instância.
+func (bu *BidUseCase) FlushAuction(ctx context.Context, auctionId string) *internal_error.InternalError {
+	if bu.syncMode {
+		return nil
+	}
+
+	request := flushRequest{auctionId: auctionId, done: make(chan struct{})}
+
+	select {
+	case bu.flushChannel <- request:
+	case <-bu.routineDone:
+		return nil
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Timeout waiting to flush auction bids")
+	}
+
+	select {
+	case <-request.done:
+		return nil
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Timeout waiting to flush auction bids")
+	}
+}
+
+func removeAuctionId(auctionOrder []string, auctionId string) []string {
+	for i, id := range auctionOrder {
+		if id == auctionId {
+			return append(auctionOrder[:i], auctionOrder[i+1:]...)
+		}
+	}
+
+	return auctionOrder
+}
+
+// Close para de aceitar lances e, no modo em lote, espera a fila ser
+// esvaziada e o último lote ser inserido. Retorna erro se o contexto expirar
+// antes disso; os lances que ficarem para trás continuam no write-ahead log.
+func (bu *BidUseCase) Close(ctx context.Context) *internal_error.InternalError {
+	bu.closeMutex.Lock()
+	if bu.closed {
+		bu.closeMutex.Unlock()
+		return nil
+	}
+	bu.closed = true
+	bu.closeMutex.Unlock()
+
+	if bu.syncMode {
+		return nil
+	}
+
+	close(bu.bidChannel)
+
+	select {
+	case <-bu.routineDone:
+		logger.Info("Bid queue drained")
+		return nil
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Timeout waiting for the bid queue to drain")
+	}
+}
+
+func (bu *BidUseCase) processBatch(ctx context.Context, bidBatch []bid_entity.Bid) {
+	results, err := bu.BidRepository.CreateBid(ctx, bidBatch)
+	if err != nil {
+		logger.Error("error trying to process bid batch list", err)
+		return
+	}
+
+	bidIds := make([]string, 0, len(bidBatch))
+	for _, bid := range bidBatch {
+		bidIds = append(bidIds, bid.Id)
+	}
+	bu.commitBidLog(bidIds)
+
+	bu.pendingBidsMutex.Lock()
+	for _, bidId := range bidIds {
+		delete(bu.pendingBids, bidId)
+	}
+	bu.pendingBidsMutex.Unlock()
+
+	for _, result := range results {
+		if result.Status == bid_entity.Rejected {
+			logger.Info("Bid rejected",
+				zap.String("bid_id", result.BidId),
+				zap.String("reason", string(result.Reason)))
+		}
+	}
+}
+
+// CreateBid no modo síncrono processa o lance na hora e devolve se ele foi
+// aceito ou recusado; no modo em lote o lance é validado, enfileirado e
+// devolvido como Queued.
 func (bu *BidUseCase) CreateBid(
 	ctx context.Context,
-	bidInputDTO BidInputDTO) *internal_error.InternalError {
+	bidInputDTO BidInputDTO) (*BidResultOutputDTO, *internal_error.InternalError) {
 
-	bidEntity, err := bid_entity.CreateBid(bidInputDTO.UserId, bidInputDTO.AuctionId, bidInputDTO.Amount)
+	var options []bid_entity.BidOption
+	if bidInputDTO.Quantity != 0 {
+		options = append(options, bid_entity.WithQuantity(bidInputDTO.Quantity))
+	}
+
+	bidEntity, err := bid_entity.CreateBid(
+		bidInputDTO.UserId, bidInputDTO.AuctionId, bidInputDTO.Amount, options...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if bu.syncMode {
+		results, err := bu.BidRepository.CreateBid(ctx, []bid_entity.Bid{*bidEntity})
+		if err != nil {
+			return nil, err
+		}
+
+		return newBidResultOutputDTO(results[0]), nil
+	}
+
+	if err := bu.BidRepository.ValidateBid(ctx, *bidEntity); err != nil {
+		return nil, err
+	}
+
+	bu.closeMutex.RLock()
+	defer bu.closeMutex.RUnlock()
+
+	if bu.closed {
+		return nil, internal_error.NewInternalServerError("Bid intake is closed")
+	}
+
+	if bu.BidLog != nil {
+		if err := bu.BidLog.Append(*bidEntity); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bu.BidRepository.QueueBid(ctx, *bidEntity); err != nil {
+		bu.commitBidLog([]string{bidEntity.Id})
+		return nil, err
+	}
+
+	bu.pendingBidsMutex.Lock()
+	bu.pendingBids[bidEntity.Id] = struct{}{}
+	bu.pendingBidsMutex.Unlock()
+
+	if err := bu.enqueueBid(ctx, *bidEntity); err != nil {
+		bu.pendingBidsMutex.Lock()
+		delete(bu.pendingBids, bidEntity.Id)
+		bu.pendingBidsMutex.Unlock()
+
+		// O contexto da requisição pode já ter expirado
+		if discardErr := bu.BidRepository.DiscardQueuedBid(context.Background(), bidEntity.Id); discardErr != nil {
+			logger.Error("Error trying to discard shed bid", discardErr)
+		}
+
+		bu.commitBidLog([]string{bidEntity.Id})
+		return nil, err
+	}
+
+	return &BidResultOutputDTO{
+		Id:     bidEntity.Id,
+		Status: BidStatus(bid_entity.Queued),
+	}, nil
+}
+
+// enqueueBid coloca o lance na fila sem bloquear indefinidamente: se a fila
+// continuar cheia após BID_ENQUEUE_TIMEOUT, ou se o contexto da requisição
+// expirar antes, o lance é descartado com service_unavailable.
+func (bu *BidUseCase) enqueueBid(ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	select {
+	case bu.bidChannel <- bidEntity:
+		bu.enqueuedTotal.Add(1)
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(bu.enqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case bu.bidChannel <- bidEntity:
+		bu.enqueuedTotal.Add(1)
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
 	}
 
-	bu.bidChannel <- *bidEntity
+	bu.shedTotal.Add(1)
+	logger.Info("Bid queue is full, shedding bid",
+		zap.String("bid_id", bidEntity.Id),
+		zap.Int("queue_depth", len(bu.bidChannel)))
 
-	return nil
+	return internal_error.NewServiceUnavailableError("Bid queue is full, try again later")
+}
+
+// replayBidLog reprocessa, em lotes e na ordem de chegada, os lances que
+// ficaram no write-ahead log sem confirmação. Lances que já têm resultado na
+// coleção (queda entre a inserção e a confirmação) são apenas confirmados.
+// Roda antes do Start do agendador e a janela do leilão é verificada no
+// instante de cada lance, então leilões que terminaram com o serviço parado
+// ainda recebem os lances confirmados ao cliente antes da queda.
+func (bu *BidUseCase) replayBidLog(ctx context.Context) {
+	pendingBids, err := bu.BidLog.Pending()
+	if err != nil {
+		logger.Error("Error trying to read pending bids from write-ahead log", err)
+		return
+	}
+
+	if len(pendingBids) == 0 {
+		return
+	}
+
+	logger.Info("Replaying bids from write-ahead log", zap.Int("count", len(pendingBids)))
+
+	var replayBatch []bid_entity.Bid
+	var processedBidIds []string
+	for _, bid := range pendingBids {
+		if processed, err := bu.BidRepository.FindBidById(ctx, bid.Id); err == nil &&
+			processed.Status != bid_entity.Queued {
+			processedBidIds = append(processedBidIds, bid.Id)
+			continue
+		}
+
+		replayBatch = append(replayBatch, bid)
+		if len(replayBatch) >= bu.maxBatchSize {
+			bu.processBatch(ctx, replayBatch)
+			replayBatch = nil
+		}
+	}
+
+	if len(replayBatch) > 0 {
+		bu.processBatch(ctx, replayBatch)
+	}
+
+	bu.commitBidLog(processedBidIds)
+}
+
+func (bu *BidUseCase) commitBidLog(bidIds []string) {
+	if bu.BidLog == nil || len(bidIds) == 0 {
+		return
+	}
+
+	if err := bu.BidLog.Commit(bidIds); err != nil {
+		logger.Error("Error trying to commit bids to write-ahead log", err)
+	}
+}
+
+func (output *BidResultOutputDTO) Rejected() bool {
+	return output.Status == BidStatus(bid_entity.Rejected)
+}
+
+func newBidResultOutputDTO(result bid_entity.BidResult) *BidResultOutputDTO {
+	return &BidResultOutputDTO{
+		Id:      result.BidId,
+		Status:  BidStatus(result.Status),
+		Reason:  string(result.Reason),
+		Message: result.Message,
+	}
 }
 
 func getMaxBatchSizeInterval() time.Duration {
@@ -125,6 +501,27 @@ func getMaxBatchSizeInterval() time.Duration {
 	return duration
 }
 
+// getBidQueueCapacity obtém a capacidade da fila de lances (padrão 100)
+func getBidQueueCapacity() int {
+	value, err := strconv.Atoi(os.Getenv("BID_QUEUE_CAPACITY"))
+	if err != nil || value <= 0 {
+		return 100
+	}
+
+	return value
+}
+
+// getBidEnqueueTimeout obtém quanto um lance espera por espaço na fila
+// antes de ser recusado (padrão 2s)
+func getBidEnqueueTimeout() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("BID_ENQUEUE_TIMEOUT"))
+	if err != nil {
+		return 2 * time.Second
+	}
+
+	return duration
+}
+
 func getMaxBatchSize() int {
 	value, err := strconv.Atoi(os.Getenv("MAX_BATCH_SIZE"))
 	if err != nil {
@@ -133,3 +530,18 @@ func getMaxBatchSize() int {
 
 	return value
 }
+
+const (
+	batchProcessingMode = "batch"
+	syncProcessingMode  = "sync"
+)
+
+// getBidProcessingMode obtém o modo de processamento dos lances: "batch"
+// (padrão) enfileira e insere em lote, "sync" processa cada lance na requisição
+func getBidProcessingMode() string {
+	if os.Getenv("BID_PROCESSING_MODE") == syncProcessingMode {
+		return syncProcessingMode
+	}
+
+	return batchProcessingMode
+}
@@ -0,0 +1,451 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MockBidRepository para testes: recusa lances abaixo de minAmount. Os
+// lances enfileirados ficam em queued até o lote gravar o resultado.
+type MockBidRepository struct {
+	minAmount float64
+	bids      []bid_entity.Bid
+	queued    map[string]bid_entity.Bid
+	mutex     sync.Mutex
+
+	// quando definido, CreateBid avisa em createStarted e espera releaseCreate
+	createStarted chan struct{}
+	releaseCreate chan struct{}
+}
+
+func (m *MockBidRepository) ValidateBid(ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	if bidEntity.Amount < m.minAmount {
+		return internal_error.NewBadRequestError("Bid amount is too low")
+	}
+	return nil
+}
+
+func (m *MockBidRepository) CreateBid(ctx context.Context, bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	if m.releaseCreate != nil {
+		m.createStarted <- struct{}{}
+		<-m.releaseCreate
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var results []bid_entity.BidResult
+	for _, bid := range bidEntities {
+		delete(m.queued, bid.Id)
+
+		if bid.Amount < m.minAmount {
+			results = append(results, bid_entity.NewRejectedBidResult(bid.Id, bid_entity.BidTooLow, "Bid amount is too low"))
+			continue
+		}
+
+		bid.Status = bid_entity.Accepted
+		m.bids = append(m.bids, bid)
+		results = append(results, bid_entity.NewAcceptedBidResult(bid.Id))
+	}
+	return results, nil
+}
+
+func (m *MockBidRepository) FindBidById(ctx context.Context, bidId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, bid := range m.bids {
+		if bid.Id == bidId {
+			return &bid, nil
+		}
+	}
+	if bid, ok := m.queued[bidId]; ok {
+		bid.Status = bid_entity.Queued
+		return &bid, nil
+	}
+	return nil, internal_error.NewNotFoundError("Bid not found")
+}
+
+func (m *MockBidRepository) QueueBid(ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.queued == nil {
+		m.queued = make(map[string]bid_entity.Bid)
+	}
+	m.queued[bidEntity.Id] = bidEntity
+	return nil
+}
+
+func (m *MockBidRepository) DiscardQueuedBid(ctx context.Context, bidId string) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.queued, bidId)
+	return nil
+}
+
+func (m *MockBidRepository) LockAuctionBids(ctx context.Context, auctionId string) (bool, func(), *internal_error.InternalError) {
+	return false, func() {}, nil
+}
+
+func (m *MockBidRepository) CreateProxyBid(ctx context.Context, proxyBid bid_entity.ProxyBid) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewInternalServerError("Proxy bids are not supported by the mock")
+}
+
+func (m *MockBidRepository) AcceptAuctionPrice(ctx context.Context, auctionId, userId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewBadRequestError("Only dutch auctions accept the current price")
+}
+
+func (m *MockBidRepository) BuyAuctionNow(ctx context.Context, auctionId, userId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewBadRequestError("Auction has no buy-it-now price")
+}
+
+func (m *MockBidRepository) VoidBidsByAuctionId(ctx context.Context, auctionId string) *internal_error.InternalError {
+	return nil
+}
+
+func (m *MockBidRepository) FindBidByAuctionId(ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]bid_entity.Bid{}, m.bids...), nil
+}
+
+func (m *MockBidRepository) FindWinningBidByAuctionId(ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("No bids found")
+}
+
+func (m *MockBidRepository) FindTopBidsByAuctionId(ctx context.Context, auctionId string, limit int64) ([]bid_entity.Bid, *internal_error.InternalError) {
+	return nil, nil
+}
+
+// MockBidLog para testes: guarda os lances pendentes em memória
+type MockBidLog struct {
+	pending []bid_entity.Bid
+	mutex   sync.Mutex
+}
+
+func (m *MockBidLog) Append(bid bid_entity.Bid) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pending = append(m.pending, bid)
+	return nil
+}
+
+func (m *MockBidLog) Commit(bidIds []string) *internal_error.InternalError {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	committed := make(map[string]bool)
+	for _, bidId := range bidIds {
+		committed[bidId] = true
+	}
+
+	var pending []bid_entity.Bid
+	for _, bid := range m.pending {
+		if !committed[bid.Id] {
+			pending = append(pending, bid)
+		}
+	}
+	m.pending = pending
+	return nil
+}
+
+func (m *MockBidLog) Pending() ([]bid_entity.Bid, *internal_error.InternalError) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]bid_entity.Bid{}, m.pending...), nil
+}
+
+func newBidInput(amount float64) BidInputDTO {
+	return BidInputDTO{
+		UserId:    uuid.New().String(),
+		AuctionId: uuid.New().String(),
+		Amount:    amount,
+	}
+}
+
+// Teste do modo síncrono: o resultado do repositório volta na resposta
+func TestCreateBidSyncMode(t *testing.T) {
+	os.Setenv("BID_PROCESSING_MODE", "sync")
+	defer os.Unsetenv("BID_PROCESSING_MODE")
+
+	repo := &MockBidRepository{minAmount: 100}
+	useCase := NewBidUseCase(repo, nil)
+
+	result, err := useCase.CreateBid(context.Background(), newBidInput(150))
+	if err != nil {
+		t.Fatalf("Erro ao criar lance: %v", err)
+	}
+	if result.Rejected() || result.Id == "" {
+		t.Errorf("Lance deveria ser aceito com id, resultado: %+v", result)
+	}
+
+	result, err = useCase.CreateBid(context.Background(), newBidInput(50))
+	if err != nil {
+		t.Fatalf("Erro ao criar lance: %v", err)
+	}
+	if !result.Rejected() || result.Reason != string(bid_entity.BidTooLow) {
+		t.Errorf("Lance deveria ser recusado por valor baixo, resultado: %+v", result)
+	}
+}
+
+// Teste do modo em lote: lances válidos são enfileirados e os inválidos
+// recusados antes de entrar na fila
+func TestCreateBidBatchMode(t *testing.T) {
+	os.Unsetenv("BID_PROCESSING_MODE")
+
+	repo := &MockBidRepository{minAmount: 100}
+	useCase := NewBidUseCase(repo, nil)
+	defer useCase.Close(context.Background())
+
+	result, err := useCase.CreateBid(context.Background(), newBidInput(150))
+	if err != nil {
+		t.Fatalf("Erro ao criar lance: %v", err)
+	}
+	if result.Status != BidStatus(bid_entity.Queued) {
+		t.Errorf("Lance deveria ser enfileirado, resultado: %+v", result)
+	}
+
+	if _, err := useCase.CreateBid(context.Background(), newBidInput(50)); err == nil {
+		t.Errorf("Lance abaixo do mínimo deveria ser recusado na validação")
+	}
+}
+
+// Teste da consulta de status: lances na fila aparecem como enfileirados,
+// inclusive para outra instância, e ids desconhecidos retornam not_found
+func TestFindBidStatus(t *testing.T) {
+	os.Unsetenv("BID_PROCESSING_MODE")
+
+	repo := &MockBidRepository{minAmount: 100}
+	useCase := NewBidUseCase(repo, nil)
+	defer useCase.Close(context.Background())
+
+	result, err := useCase.CreateBid(context.Background(), newBidInput(150))
+	if err != nil {
+		t.Fatalf("Erro ao criar lance: %v", err)
+	}
+
+	status, err := useCase.FindBidStatus(context.Background(), result.Id)
+	if err != nil {
+		t.Fatalf("Erro ao consultar status do lance: %v", err)
+	}
+	if status.Status != BidStatus(bid_entity.Queued) {
+		t.Errorf("Lance deveria estar enfileirado, resultado: %+v", status)
+	}
+
+	// Outra instância (ou a mesma após um reinício) lê o status do banco
+	otherUseCase := NewBidUseCase(repo, nil)
+	defer otherUseCase.Close(context.Background())
+
+	status, err = otherUseCase.FindBidStatus(context.Background(), result.Id)
+	if err != nil || status.Status != BidStatus(bid_entity.Queued) {
+		t.Errorf("Lance deveria estar enfileirado para outra instância, resultado: %+v (erro: %v)", status, err)
+	}
+
+	if _, err := useCase.FindBidStatus(context.Background(), uuid.New().String()); err == nil || err.Err != "not_found" {
+		t.Errorf("Lance desconhecido deveria retornar not_found, erro: %v", err)
+	}
+}
+
+// Teste do write-ahead log: lances enfileirados são registrados antes da
+// resposta e os pendentes de uma execução anterior são reprocessados
+func TestCreateBidWriteAheadLog(t *testing.T) {
+	os.Unsetenv("BID_PROCESSING_MODE")
+
+	alreadyInserted, _ := bid_entity.CreateBid(uuid.New().String(), uuid.New().String(), 200)
+	notInserted, _ := bid_entity.CreateBid(uuid.New().String(), uuid.New().String(), 300)
+	alreadyInserted.Status = bid_entity.Accepted
+
+	repo := &MockBidRepository{minAmount: 100, bids: []bid_entity.Bid{*alreadyInserted}}
+	bidLog := &MockBidLog{pending: []bid_entity.Bid{*alreadyInserted, *notInserted}}
+
+	useCase := NewBidUseCase(repo, bidLog)
+	defer useCase.Close(context.Background())
+
+	if pending, _ := bidLog.Pending(); len(pending) != 0 {
+		t.Errorf("Lances pendentes deveriam ter sido reprocessados, restantes: %+v", pending)
+	}
+	if bids, _ := repo.FindBidByAuctionId(context.Background(), ""); len(bids) != 2 {
+		t.Errorf("Lance já inserido não deveria ser duplicado, lances: %+v", bids)
+	}
+
+	result, err := useCase.CreateBid(context.Background(), newBidInput(150))
+	if err != nil {
+		t.Fatalf("Erro ao criar lance: %v", err)
+	}
+
+	pending, _ := bidLog.Pending()
+	if len(pending) != 1 || pending[0].Id != result.Id {
+		t.Errorf("Lance enfileirado deveria estar no log, pendentes: %+v", pending)
+	}
+}
+
+// Teste do encerramento: Close insere o lote pendente e recusa novos lances
+func TestCloseFlushesPendingBatch(t *testing.T) {
+	os.Unsetenv("BID_PROCESSING_MODE")
+	os.Setenv("BATCH_INSERT_INTERVAL", "1h")
+	defer os.Unsetenv("BATCH_INSERT_INTERVAL")
+
+	repo := &MockBidRepository{minAmount: 100}
+	useCase := NewBidUseCase(repo, nil)
+
+	if _, err := useCase.CreateBid(context.Background(), newBidInput(150)); err != nil {
+		t.Fatalf("Erro ao criar lance: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := useCase.Close(ctx); err != nil {
+		t.Fatalf("Erro ao encerrar o caso de uso: %v", err)
+	}
+
+	if bids, _ := repo.FindBidByAuctionId(context.Background(), ""); len(bids) != 1 {
+		t.Errorf("Lote pendente deveria ter sido inserido no encerramento, lances: %+v", bids)
+	}
+
+	if _, err := useCase.CreateBid(context.Background(), newBidInput(150)); err == nil {
+		t.Errorf("Lances deveriam ser recusados após o encerramento")
+	}
+}
+
+func waitForBids(repo *MockBidRepository, count int) []bid_entity.Bid {
+	deadline := time.Now().Add(time.Second)
+	for {
+		bids, _ := repo.FindBidByAuctionId(context.Background(), "")
+		if len(bids) >= count || time.Now().After(deadline) {
+			return bids
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Teste dos lotes por leilão: um leilão que atinge MAX_BATCH_SIZE é inserido
+// na ordem de chegada sem levar junto os lances de outros leilões, e cada
+// instância mantém os próprios lotes
+func TestBatchesArePartitionedByAuction(t *testing.T) {
+	os.Unsetenv("BID_PROCESSING_MODE")
+	os.Setenv("BATCH_INSERT_INTERVAL", "1h")
+	os.Setenv("MAX_BATCH_SIZE", "2")
+	defer os.Unsetenv("BATCH_INSERT_INTERVAL")
+	defer os.Unsetenv("MAX_BATCH_SIZE")
+
+	repo := &MockBidRepository{minAmount: 100}
+	useCase := NewBidUseCase(repo, nil)
+
+	otherRepo := &MockBidRepository{minAmount: 100}
+	otherUseCase := NewBidUseCase(otherRepo, nil)
+
+	auctionA, auctionB := newBidInput(150), newBidInput(150)
+	firstA, _ := useCase.CreateBid(context.Background(), auctionA)
+	useCase.CreateBid(context.Background(), auctionB)
+	auctionA.Amount = 160
+	secondA, _ := useCase.CreateBid(context.Background(), auctionA)
+
+	otherUseCase.CreateBid(context.Background(), newBidInput(150))
+
+	bids := waitForBids(repo, 2)
+	if len(bids) != 2 || bids[0].Id != firstA.Id || bids[1].Id != secondA.Id {
+		t.Fatalf("Apenas o lote do leilão A deveria ser inserido, em ordem, lances: %+v", bids)
+	}
+
+	if err := useCase.Close(context.Background()); err != nil {
+		t.Fatalf("Erro ao encerrar o caso de uso: %v", err)
+	}
+	if bids, _ := repo.FindBidByAuctionId(context.Background(), ""); len(bids) != 3 {
+		t.Errorf("Lote do leilão B deveria ser inserido no encerramento, lances: %+v", bids)
+	}
+
+	if err := otherUseCase.Close(context.Background()); err != nil {
+		t.Fatalf("Erro ao encerrar o caso de uso: %v", err)
+	}
+	if bids, _ := otherRepo.FindBidByAuctionId(context.Background(), ""); len(bids) != 1 {
+		t.Errorf("Cada instância deveria inserir apenas os próprios lances, lances: %+v", bids)
+	}
+}
+
+// Teste do pré-fechamento: FlushAuction insere na hora apenas o lote do leilão
+// que vai ser fechado, sem esperar o BATCH_INSERT_INTERVAL
+func TestFlushAuctionProcessesQueuedBids(t *testing.T) {
+	os.Unsetenv("BID_PROCESSING_MODE")
+	os.Setenv("BATCH_INSERT_INTERVAL", "1h")
+	defer os.Unsetenv("BATCH_INSERT_INTERVAL")
+
+	repo := &MockBidRepository{minAmount: 100}
+	useCase := NewBidUseCase(repo, nil)
+	defer useCase.Close(context.Background())
+
+	closing, other := newBidInput(150), newBidInput(150)
+	queued, _ := useCase.CreateBid(context.Background(), closing)
+	useCase.CreateBid(context.Background(), other)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := useCase.FlushAuction(ctx, closing.AuctionId); err != nil {
+		t.Fatalf("Erro ao processar os lances do leilão: %v", err)
+	}
+
+	bids, _ := repo.FindBidByAuctionId(context.Background(), "")
+	if len(bids) != 1 || bids[0].Id != queued.Id {
+		t.Errorf("Apenas o lance do leilão fechado deveria ser inserido, lances: %+v", bids)
+	}
+
+	if status, _ := useCase.FindBidStatus(context.Background(), queued.Id); status == nil ||
+		status.Status != BidStatus(bid_entity.Accepted) {
+		t.Errorf("Lance deveria estar aceito antes do fechamento, status: %+v", status)
+	}
+}
+
+// Teste de backpressure: com a fila cheia o lance é recusado com
+// service_unavailable após o timeout, ou na hora se o contexto já expirou,
+// e não fica no write-ahead log
+func TestCreateBidShedsLoadWhenQueueIsFull(t *testing.T) {
+	os.Unsetenv("BID_PROCESSING_MODE")
+	os.Setenv("MAX_BATCH_SIZE", "1")
+	os.Setenv("BID_QUEUE_CAPACITY", "1")
+	os.Setenv("BID_ENQUEUE_TIMEOUT", "20ms")
+	defer os.Unsetenv("MAX_BATCH_SIZE")
+	defer os.Unsetenv("BID_QUEUE_CAPACITY")
+	defer os.Unsetenv("BID_ENQUEUE_TIMEOUT")
+
+	repo := &MockBidRepository{
+		minAmount:     100,
+		createStarted: make(chan struct{}, 10),
+		releaseCreate: make(chan struct{}),
+	}
+	bidLog := &MockBidLog{}
+	useCase := NewBidUseCase(repo, bidLog)
+
+	// o primeiro lance ocupa o consumidor e o segundo enche a fila
+	useCase.CreateBid(context.Background(), newBidInput(150))
+	<-repo.createStarted
+	if _, err := useCase.CreateBid(context.Background(), newBidInput(150)); err != nil {
+		t.Fatalf("Segundo lance deveria caber na fila: %v", err)
+	}
+
+	_, err := useCase.CreateBid(context.Background(), newBidInput(150))
+	if err == nil || err.Err != "service_unavailable" {
+		t.Fatalf("Lance com a fila cheia deveria retornar service_unavailable, erro: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := useCase.CreateBid(ctx, newBidInput(150)); err == nil || err.Err != "service_unavailable" {
+		t.Errorf("Lance com contexto expirado deveria retornar service_unavailable, erro: %v", err)
+	}
+
+	stats := useCase.FindBidQueueStats(context.Background())
+	if stats.Capacity != 1 || stats.Depth != 1 || stats.ShedTotal != 2 || stats.EnqueuedTotal != 2 {
+		t.Errorf("Estatísticas inesperadas da fila: %+v", stats)
+	}
+	if pending, _ := bidLog.Pending(); len(pending) != 2 {
+		t.Errorf("Lances recusados não deveriam ficar no log, pendentes: %+v", pending)
+	}
+
+	close(repo.releaseCreate)
+	useCase.Close(context.Background())
+}
@@ -0,0 +1,46 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+type ProxyBidInputDTO struct {
+	UserId    string  `json:"user_id"`
+	AuctionId string  `json:"auction_id"`
+	MaxAmount float64 `json:"max_amount"`
+}
+
+// ProxyBidOutputDTO informa a situação do usuário após o registro do teto,
+// sem expor o teto de ninguém
+type ProxyBidOutputDTO struct {
+	UserId       string  `json:"user_id"`
+	AuctionId    string  `json:"auction_id"`
+	Winning      bool    `json:"winning"`
+	CurrentPrice float64 `json:"current_price"`
+}
+
+// CreateProxyBid é processado na requisição mesmo no modo em lote, para que
+// o lance automático resultante já conste na resposta
+func (bu *BidUseCase) CreateProxyBid(
+	ctx context.Context,
+	proxyBidInputDTO ProxyBidInputDTO) (*ProxyBidOutputDTO, *internal_error.InternalError) {
+	proxyBid, err := bid_entity.CreateProxyBid(
+		proxyBidInputDTO.UserId, proxyBidInputDTO.AuctionId, proxyBidInputDTO.MaxAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	highestBid, err := bu.BidRepository.CreateProxyBid(ctx, *proxyBid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProxyBidOutputDTO{
+		UserId:       proxyBid.UserId,
+		AuctionId:    proxyBid.AuctionId,
+		Winning:      highestBid.UserId == proxyBid.UserId,
+		CurrentPrice: highestBid.Amount,
+	}, nil
+}
@@ -19,6 +19,7 @@ func (bu *BidUseCase) FindBidByAuctionId(
 			UserId:    bid.UserId,
 			AuctionId: bid.AuctionId,
 			Amount:    bid.Amount,
+			Quantity:  bid.Quantity,
 			Timestamp: bid.Timestamp,
 		})
 	}
@@ -38,8 +39,48 @@ func (bu *BidUseCase) FindWinningBidByAuctionId(
 		UserId:    bidEntity.UserId,
 		AuctionId: bidEntity.AuctionId,
 		Amount:    bidEntity.Amount,
+		Quantity:  bidEntity.Quantity,
 		Timestamp: bidEntity.Timestamp,
 	}
 
 	return bidOutput, nil
 }
+
+// FindBidStatus consulta o resultado de um lance na coleção de lances. Os
+// lances enfileirados são gravados como Queued e o lote grava o resultado no
+// mesmo documento, então a consulta vale para qualquer instância.
+func (bu *BidUseCase) FindBidStatus(
+	ctx context.Context, bidId string) (*BidResultOutputDTO, *internal_error.InternalError) {
+	bidEntity, err := bu.BidRepository.FindBidById(ctx, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BidResultOutputDTO{
+		Id:      bidEntity.Id,
+		Status:  BidStatus(bidEntity.Status),
+		Reason:  string(bidEntity.RejectionReason),
+		Message: bidEntity.RejectionMessage,
+	}, nil
+}
+
+func (bu *BidUseCase) FindBidQueueStats(ctx context.Context) *BidQueueStatsOutputDTO {
+	bu.pendingBidsMutex.Lock()
+	pendingBids := len(bu.pendingBids)
+	bu.pendingBidsMutex.Unlock()
+
+	mode := batchProcessingMode
+	if bu.syncMode {
+		mode = syncProcessingMode
+	}
+
+	return &BidQueueStatsOutputDTO{
+		Mode:             mode,
+		Capacity:         cap(bu.bidChannel),
+		Depth:            len(bu.bidChannel),
+		PendingBids:      pendingBids,
+		EnqueuedTotal:    bu.enqueuedTotal.Load(),
+		ShedTotal:        bu.shedTotal.Load(),
+		EnqueueTimeoutMs: bu.enqueueTimeout.Milliseconds(),
+	}
+}